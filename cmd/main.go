@@ -9,8 +9,10 @@ import (
 	"os/signal"
 	"syscall"
 	"time"
+	_ "time/tzdata" // schedule timezones on images without tzdata
 
 	"github.com/mrsool/netbird-gitops/pkg/controller"
+	"github.com/mrsool/netbird-gitops/pkg/secrets"
 	"github.com/go-git/go-git/v5/plumbing/transport"
 	"github.com/go-git/go-git/v5/plumbing/transport/http"
 	"github.com/go-git/go-git/v5/plumbing/transport/ssh"
@@ -38,6 +40,10 @@ var (
 	logLevel              = flag.String("log-level", os.Getenv("LOG_LEVEL"), "Log level (debug, info, warn, error)")
 	syncExit              = flag.Bool("sync-and-exit", false, "Force sync once and exit")
 	notifyServicesPath    = flag.String("notify-services-path", "notify.yaml", "Path to notification services configuration yaml")
+	secretsDir            = flag.String("secrets-dir", os.Getenv("SECRETS_DIR"), "Directory to write generated secrets (e.g. service user tokens, setup keys) to")
+	secretsWebhookURL     = flag.String("secrets-webhook-url", os.Getenv("SECRETS_WEBHOOK_URL"), "URL generated secrets are posted to as JSON {\"name\": ..., \"value\": ...}")
+	writeBackBranch       = flag.String("write-back-branch", os.Getenv("WRITE_BACK_BRANCH"), "Branch to push peers and users missing from Git to for review, must differ from --git-branch")
+	writeBackPatchPath    = flag.String("write-back-patch-path", os.Getenv("WRITE_BACK_PATCH_PATH"), "Path to write peers and users missing from Git to as a patch")
 )
 
 func main() {
@@ -111,19 +117,40 @@ func main() {
 		os.Exit(1)
 	}
 
+	if *writeBackBranch != "" && *writeBackBranch == *gitBranch {
+		flag.PrintDefaults()
+		fmt.Println("--write-back-branch must differ from --git-branch")
+		os.Exit(1)
+	}
+
 	err := setupNotifiers()
 	if err != nil {
 		slog.Warn("Error setting up notifications", "err", err)
 	}
 
+	var secretSinks secrets.MultiSink
+	if *secretsDir != "" {
+		secretSinks = append(secretSinks, secrets.NewFileSink(*secretsDir))
+	}
+	if *secretsWebhookURL != "" {
+		secretSinks = append(secretSinks, secrets.NewWebhookSink(*secretsWebhookURL))
+	}
+	var secretSink secrets.Sink
+	if len(secretSinks) > 0 {
+		secretSink = secretSinks
+	}
+
 	ctrl := controller.NewController(controller.Options{
-		GitRepoURL:      *gitRepoURL,
-		GitRelativePath: *gitRelativePath,
-		GitBranch:       *gitBranch,
-		GitAuth:         gitAuth,
-		NetBirdToken:    *netbirdToken,
-		NetBirdAPI:      *netbirdManagementAPI,
-		SyncOnceAndExit: *syncExit,
+		GitRepoURL:         *gitRepoURL,
+		GitRelativePath:    *gitRelativePath,
+		GitBranch:          *gitBranch,
+		GitAuth:            gitAuth,
+		NetBirdToken:       *netbirdToken,
+		NetBirdAPI:         *netbirdManagementAPI,
+		SyncOnceAndExit:    *syncExit,
+		SecretSink:         secretSink,
+		WriteBackBranch:    *writeBackBranch,
+		WriteBackPatchPath: *writeBackPatchPath,
 	})
 
 	ctx, cancel := context.WithCancel(context.Background())
@@ -0,0 +1,59 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+
+	"github.com/mrsool/netbird-gitops/pkg/data"
+)
+
+// ListAccounts lists NetBird accounts accessible by the API token
+func (c Client) ListAccounts(ctx context.Context) ([]data.Account, error) {
+	respBytes, err := c.doRequest(ctx, "GET", "accounts", nil)
+	if err != nil {
+		return nil, fmt.Errorf("NetBird API: ListAccounts: %w", err)
+	}
+	var ret []data.Account
+
+	err = json.Unmarshal(respBytes, &ret)
+	if err != nil {
+		return nil, fmt.Errorf("NetBird API: ListAccounts: %w", err)
+	}
+
+	// Keep all settings, updates must send back the ones not modelled
+	var raw []struct {
+		Settings map[string]interface{} `json:"settings"`
+	}
+	err = json.Unmarshal(respBytes, &raw)
+	if err != nil {
+		return nil, fmt.Errorf("NetBird API: ListAccounts: %w", err)
+	}
+	for idx := range ret {
+		ret[idx].RawSettings = raw[idx].Settings
+		if ret[idx].RawSettings == nil {
+			ret[idx].RawSettings = make(map[string]interface{})
+		}
+	}
+
+	return ret, nil
+}
+
+// UpdateAccount updates NetBird account settings to account.RawSettings
+func (c Client) UpdateAccount(ctx context.Context, account data.Account) error {
+	if c.DryRun {
+		slog.Info("DryRun==True")
+		return nil
+	}
+
+	body := map[string]interface{}{
+		"settings": account.RawSettings,
+	}
+
+	_, err := c.doRequest(ctx, "PUT", "accounts/"+account.ID, body)
+	if err != nil {
+		return fmt.Errorf("NetBird API: UpdateAccount: %w", err)
+	}
+	return nil
+}
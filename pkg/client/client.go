@@ -32,6 +32,16 @@ func NewClient(managementAPI, token string, dryRun bool) *Client {
 }
 
 func (c Client) doRequest(ctx context.Context, method, resource string, body interface{}) ([]byte, error) {
+	return c.request(ctx, method, resource, body, false)
+}
+
+// doSensitiveRequest same as doRequest, without logging the response body as it
+// contains secrets
+func (c Client) doSensitiveRequest(ctx context.Context, method, resource string, body interface{}) ([]byte, error) {
+	return c.request(ctx, method, resource, body, true)
+}
+
+func (c Client) request(ctx context.Context, method, resource string, body interface{}, sensitive bool) ([]byte, error) {
 	t1 := time.Now()
 	slog.Info(method+" /api/"+resource, "body", body)
 	var bodyReader io.Reader
@@ -67,7 +77,9 @@ func (c Client) doRequest(ctx context.Context, method, resource string, body int
 		return nil, err
 	}
 
-	slog.Debug(method+" /api/"+resource, "response", string(respBytes), "time", time.Since(t1))
+	if !sensitive {
+		slog.Debug(method+" /api/"+resource, "response", string(respBytes), "time", time.Since(t1))
+	}
 	slog.Info(method+" /api/"+resource, "response_code", resp.StatusCode, "time", time.Since(t1), "content_size", len(respBytes))
 
 	if resp.StatusCode > 299 {
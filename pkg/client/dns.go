@@ -10,34 +10,38 @@ import (
 )
 
 // GetDNSSettings Get NetBird DNS settings
-func (c Client) GetDNSSettings(ctx context.Context) (data.DNSResponse, error) {
+func (c Client) GetDNSSettings(ctx context.Context) (data.DNS, error) {
 	respBytes, err := c.doRequest(ctx, "GET", "dns/settings", nil)
 	if err != nil {
-		return data.DNSResponse{}, fmt.Errorf("NetBird API: GetDNSSettings: %w", err)
+		return data.DNS{}, fmt.Errorf("NetBird API: GetDNSSettings: %w", err)
 	}
-	var ret data.DNSResponse
+	var ret data.DNS
 
 	err = json.Unmarshal(respBytes, &ret)
 	if err != nil {
-		return data.DNSResponse{}, fmt.Errorf("NetBird API: GetDNSSettings: %w", err)
+		return data.DNS{}, fmt.Errorf("NetBird API: GetDNSSettings: %w", err)
 	}
 
 	return ret, nil
 }
 
-// UpdateDNSSettings Update NetBird DNS settings
+// UpdateDNSSettings Update NetBird DNS settings, groups are IDs
 func (c Client) UpdateDNSSettings(ctx context.Context, settings data.DNS) error {
 	if c.DryRun {
 		slog.Info("DryRun==True")
 		return nil
 	}
 
-	body, err := json.Marshal(settings)
-	if err != nil {
-		return err
+	// An empty list enables DNS management for all groups, null is rejected
+	groups := settings.DisableFor
+	if groups == nil {
+		groups = []string{}
+	}
+	body := map[string]interface{}{
+		"disabled_management_groups": groups,
 	}
 
-	_, err = c.doRequest(ctx, "POST", "dns/settings", body)
+	_, err := c.doRequest(ctx, "PUT", "dns/settings", body)
 	if err != nil {
 		return fmt.Errorf("NetBird API: UpdateDNSSettings: %w", err)
 	}
@@ -68,12 +72,7 @@ func (c Client) UpdateNameserver(ctx context.Context, nameserver data.Nameserver
 		return nil
 	}
 
-	body, err := json.Marshal(nameserver)
-	if err != nil {
-		return err
-	}
-
-	_, err = c.doRequest(ctx, "PUT", "dns/nameservers/"+nameserver.ID, body)
+	_, err := c.doRequest(ctx, "PUT", "dns/nameservers/"+nameserver.ID, nameserverBody(nameserver))
 	if err != nil {
 		return fmt.Errorf("NetBird API: UpdateNameserver: %w", err)
 	}
@@ -87,12 +86,7 @@ func (c Client) CreateNameserver(ctx context.Context, nameserver data.Nameserver
 		return nil
 	}
 
-	body, err := json.Marshal(nameserver)
-	if err != nil {
-		return err
-	}
-
-	_, err = c.doRequest(ctx, "POST", "dns/nameservers", body)
+	_, err := c.doRequest(ctx, "POST", "dns/nameservers", nameserverBody(nameserver))
 	if err != nil {
 		return fmt.Errorf("NetBird API: CreateNameserver: %w", err)
 	}
@@ -113,3 +107,16 @@ func (c Client) DeleteNameserver(ctx context.Context, nameserver data.Nameserver
 	}
 	return nil
 }
+
+func nameserverBody(nameserver data.Nameserver) map[string]interface{} {
+	return map[string]interface{}{
+		"name":                   nameserver.Name,
+		"description":            nameserver.Description,
+		"nameservers":            nameserver.Nameservers,
+		"enabled":                nameserver.Enabled,
+		"groups":                 nameserver.Groups,
+		"primary":                nameserver.Primary,
+		"domains":                nameserver.Domains,
+		"search_domains_enabled": nameserver.SearchDomainsEnabled,
+	}
+}
@@ -0,0 +1,153 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+
+	"github.com/mrsool/netbird-gitops/pkg/data"
+)
+
+// ListDNSZones lists all NetBird custom DNS zones
+func (c Client) ListDNSZones(ctx context.Context) ([]data.DNSZone, error) {
+	respBytes, err := c.doRequest(ctx, "GET", "dns/zones", nil)
+	if err != nil {
+		return nil, fmt.Errorf("NetBird API: ListDNSZones: %w", err)
+	}
+	var ret []data.DNSZone
+
+	err = json.Unmarshal(respBytes, &ret)
+	if err != nil {
+		return nil, fmt.Errorf("NetBird API: ListDNSZones: %w", err)
+	}
+
+	return ret, nil
+}
+
+// CreateDNSZone creates a NetBird custom DNS zone
+func (c Client) CreateDNSZone(ctx context.Context, zone data.DNSZone) (data.DNSZone, error) {
+	if c.DryRun {
+		zone.ID = zone.Domain
+		return zone, nil
+	}
+
+	respBytes, err := c.doRequest(ctx, "POST", "dns/zones", dnsZoneBody(zone))
+	if err != nil {
+		return data.DNSZone{}, fmt.Errorf("NetBird API: CreateDNSZone: %w", err)
+	}
+
+	var ret data.DNSZone
+
+	err = json.Unmarshal(respBytes, &ret)
+	if err != nil {
+		return ret, fmt.Errorf("NetBird API: CreateDNSZone: %w", err)
+	}
+
+	return ret, nil
+}
+
+// UpdateDNSZone updates a NetBird custom DNS zone
+func (c Client) UpdateDNSZone(ctx context.Context, zone data.DNSZone) error {
+	if c.DryRun {
+		slog.Info("DryRun==True")
+		return nil
+	}
+
+	_, err := c.doRequest(ctx, "PUT", "dns/zones/"+zone.ID, dnsZoneBody(zone))
+	if err != nil {
+		return fmt.Errorf("NetBird API: UpdateDNSZone: %w", err)
+	}
+	return nil
+}
+
+// DeleteDNSZone deletes a NetBird custom DNS zone with its records
+func (c Client) DeleteDNSZone(ctx context.Context, zone data.DNSZone) error {
+	if c.DryRun {
+		slog.Info("DryRun==True")
+		return nil
+	}
+
+	_, err := c.doRequest(ctx, "DELETE", "dns/zones/"+zone.ID, nil)
+	if err != nil {
+		return fmt.Errorf("NetBird API: DeleteDNSZone: %w", err)
+	}
+	return nil
+}
+
+func dnsZoneBody(zone data.DNSZone) map[string]interface{} {
+	return map[string]interface{}{
+		"name":                 zone.Name,
+		"domain":               zone.Domain,
+		"enabled":              zone.Enabled,
+		"enable_search_domain": zone.EnableSearchDomain,
+		"distribution_groups":  zone.DistributionGroups,
+	}
+}
+
+// ListDNSRecords lists records of a NetBird custom DNS zone
+func (c Client) ListDNSRecords(ctx context.Context, zone data.DNSZone) ([]data.DNSRecord, error) {
+	respBytes, err := c.doRequest(ctx, "GET", "dns/zones/"+zone.ID+"/records", nil)
+	if err != nil {
+		return nil, fmt.Errorf("NetBird API: ListDNSRecords: %w", err)
+	}
+	var ret []data.DNSRecord
+
+	err = json.Unmarshal(respBytes, &ret)
+	if err != nil {
+		return nil, fmt.Errorf("NetBird API: ListDNSRecords: %w", err)
+	}
+
+	return ret, nil
+}
+
+// CreateDNSRecord creates a record in a NetBird custom DNS zone
+func (c Client) CreateDNSRecord(ctx context.Context, zone data.DNSZone, record data.DNSRecord) error {
+	if c.DryRun {
+		slog.Info("DryRun==True")
+		return nil
+	}
+
+	_, err := c.doRequest(ctx, "POST", "dns/zones/"+zone.ID+"/records", dnsRecordBody(record))
+	if err != nil {
+		return fmt.Errorf("NetBird API: CreateDNSRecord: %w", err)
+	}
+	return nil
+}
+
+// UpdateDNSRecord updates a record in a NetBird custom DNS zone
+func (c Client) UpdateDNSRecord(ctx context.Context, zone data.DNSZone, record data.DNSRecord) error {
+	if c.DryRun {
+		slog.Info("DryRun==True")
+		return nil
+	}
+
+	_, err := c.doRequest(ctx, "PUT", "dns/zones/"+zone.ID+"/records/"+record.ID, dnsRecordBody(record))
+	if err != nil {
+		return fmt.Errorf("NetBird API: UpdateDNSRecord: %w", err)
+	}
+	return nil
+}
+
+// DeleteDNSRecord deletes a record from a NetBird custom DNS zone
+func (c Client) DeleteDNSRecord(ctx context.Context, zone data.DNSZone, record data.DNSRecord) error {
+	if c.DryRun {
+		slog.Info("DryRun==True")
+		return nil
+	}
+
+	_, err := c.doRequest(ctx, "DELETE", "dns/zones/"+zone.ID+"/records/"+record.ID, nil)
+	if err != nil {
+		return fmt.Errorf("NetBird API: DeleteDNSRecord: %w", err)
+	}
+	return nil
+}
+
+func dnsRecordBody(record data.DNSRecord) map[string]interface{} {
+	return map[string]interface{}{
+		"name":    record.Name,
+		"type":    record.Type,
+		"content": record.Content,
+		"ttl":     record.GetTTL(),
+	}
+}
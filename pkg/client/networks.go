@@ -0,0 +1,237 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+
+	"github.com/mrsool/netbird-gitops/pkg/data"
+	"github.com/mrsool/netbird-gitops/pkg/util"
+)
+
+// ListNetworks lists all NetBird networks
+func (c Client) ListNetworks(ctx context.Context) ([]data.Network, error) {
+	respBytes, err := c.doRequest(ctx, "GET", "networks", nil)
+	if err != nil {
+		return nil, fmt.Errorf("NetBird API: ListNetworks: %w", err)
+	}
+	var ret []data.Network
+
+	err = json.Unmarshal(respBytes, &ret)
+	if err != nil {
+		return nil, fmt.Errorf("NetBird API: ListNetworks: %w", err)
+	}
+
+	return ret, nil
+}
+
+// CreateNetwork creates a NetBird network
+func (c Client) CreateNetwork(ctx context.Context, network data.Network) (data.Network, error) {
+	if c.DryRun {
+		network.ID = network.Name
+		return network, nil
+	}
+
+	body := map[string]interface{}{
+		"name":        network.Name,
+		"description": network.Description,
+	}
+
+	respBytes, err := c.doRequest(ctx, "POST", "networks", body)
+	if err != nil {
+		return data.Network{}, fmt.Errorf("NetBird API: CreateNetwork: %w", err)
+	}
+
+	var ret data.Network
+
+	err = json.Unmarshal(respBytes, &ret)
+	if err != nil {
+		return ret, fmt.Errorf("NetBird API: CreateNetwork: %w", err)
+	}
+
+	return ret, nil
+}
+
+// UpdateNetwork updates a NetBird network
+func (c Client) UpdateNetwork(ctx context.Context, network data.Network) error {
+	if c.DryRun {
+		slog.Info("DryRun==True")
+		return nil
+	}
+
+	body := map[string]interface{}{
+		"name":        network.Name,
+		"description": network.Description,
+	}
+
+	_, err := c.doRequest(ctx, "PUT", "networks/"+network.ID, body)
+	if err != nil {
+		return fmt.Errorf("NetBird API: UpdateNetwork: %w", err)
+	}
+	return nil
+}
+
+// DeleteNetwork deletes a NetBird network
+func (c Client) DeleteNetwork(ctx context.Context, network data.Network) error {
+	if c.DryRun {
+		slog.Info("DryRun==True")
+		return nil
+	}
+
+	_, err := c.doRequest(ctx, "DELETE", "networks/"+network.ID, nil)
+	if err != nil {
+		return fmt.Errorf("NetBird API: DeleteNetwork: %w", err)
+	}
+	return nil
+}
+
+// ListNetworkResources lists resources of a NetBird network
+func (c Client) ListNetworkResources(ctx context.Context, network data.Network) ([]data.NetworkResource, error) {
+	respBytes, err := c.doRequest(ctx, "GET", "networks/"+network.ID+"/resources", nil)
+	if err != nil {
+		return nil, fmt.Errorf("NetBird API: ListNetworkResources: %w", err)
+	}
+	var ret []data.NetworkResource
+
+	err = json.Unmarshal(respBytes, &ret)
+	if err != nil {
+		return nil, fmt.Errorf("NetBird API: ListNetworkResources: %w", err)
+	}
+
+	return ret, nil
+}
+
+// CreateNetworkResource creates a resource in a NetBird network
+func (c Client) CreateNetworkResource(ctx context.Context, network data.Network, resource data.NetworkResource) (data.NetworkResource, error) {
+	if c.DryRun {
+		resource.ID = resource.Name
+		return resource, nil
+	}
+
+	respBytes, err := c.doRequest(ctx, "POST", "networks/"+network.ID+"/resources", networkResourceBody(resource))
+	if err != nil {
+		return data.NetworkResource{}, fmt.Errorf("NetBird API: CreateNetworkResource: %w", err)
+	}
+
+	var ret data.NetworkResource
+
+	err = json.Unmarshal(respBytes, &ret)
+	if err != nil {
+		return ret, fmt.Errorf("NetBird API: CreateNetworkResource: %w", err)
+	}
+
+	return ret, nil
+}
+
+// UpdateNetworkResource updates a resource in a NetBird network
+func (c Client) UpdateNetworkResource(ctx context.Context, network data.Network, resource data.NetworkResource) error {
+	if c.DryRun {
+		slog.Info("DryRun==True")
+		return nil
+	}
+
+	_, err := c.doRequest(ctx, "PUT", "networks/"+network.ID+"/resources/"+resource.ID, networkResourceBody(resource))
+	if err != nil {
+		return fmt.Errorf("NetBird API: UpdateNetworkResource: %w", err)
+	}
+	return nil
+}
+
+// DeleteNetworkResource deletes a resource from a NetBird network
+func (c Client) DeleteNetworkResource(ctx context.Context, network data.Network, resource data.NetworkResource) error {
+	if c.DryRun {
+		slog.Info("DryRun==True")
+		return nil
+	}
+
+	_, err := c.doRequest(ctx, "DELETE", "networks/"+network.ID+"/resources/"+resource.ID, nil)
+	if err != nil {
+		return fmt.Errorf("NetBird API: DeleteNetworkResource: %w", err)
+	}
+	return nil
+}
+
+func networkResourceBody(resource data.NetworkResource) map[string]interface{} {
+	return map[string]interface{}{
+		"name":        resource.Name,
+		"description": resource.Description,
+		"address":     resource.Address,
+		"enabled":     resource.Enabled,
+		"groups":      util.Map(resource.Groups, func(g data.Group) string { return g.ID }),
+	}
+}
+
+// ListNetworkRouters lists routers of a NetBird network
+func (c Client) ListNetworkRouters(ctx context.Context, network data.Network) ([]data.NetworkRouter, error) {
+	respBytes, err := c.doRequest(ctx, "GET", "networks/"+network.ID+"/routers", nil)
+	if err != nil {
+		return nil, fmt.Errorf("NetBird API: ListNetworkRouters: %w", err)
+	}
+	var ret []data.NetworkRouter
+
+	err = json.Unmarshal(respBytes, &ret)
+	if err != nil {
+		return nil, fmt.Errorf("NetBird API: ListNetworkRouters: %w", err)
+	}
+
+	return ret, nil
+}
+
+// CreateNetworkRouter creates a router in a NetBird network
+func (c Client) CreateNetworkRouter(ctx context.Context, network data.Network, router data.NetworkRouter) error {
+	if c.DryRun {
+		slog.Info("DryRun==True")
+		return nil
+	}
+
+	_, err := c.doRequest(ctx, "POST", "networks/"+network.ID+"/routers", networkRouterBody(router))
+	if err != nil {
+		return fmt.Errorf("NetBird API: CreateNetworkRouter: %w", err)
+	}
+	return nil
+}
+
+// UpdateNetworkRouter updates a router in a NetBird network
+func (c Client) UpdateNetworkRouter(ctx context.Context, network data.Network, router data.NetworkRouter) error {
+	if c.DryRun {
+		slog.Info("DryRun==True")
+		return nil
+	}
+
+	_, err := c.doRequest(ctx, "PUT", "networks/"+network.ID+"/routers/"+router.ID, networkRouterBody(router))
+	if err != nil {
+		return fmt.Errorf("NetBird API: UpdateNetworkRouter: %w", err)
+	}
+	return nil
+}
+
+// DeleteNetworkRouter deletes a router from a NetBird network
+func (c Client) DeleteNetworkRouter(ctx context.Context, network data.Network, router data.NetworkRouter) error {
+	if c.DryRun {
+		slog.Info("DryRun==True")
+		return nil
+	}
+
+	_, err := c.doRequest(ctx, "DELETE", "networks/"+network.ID+"/routers/"+router.ID, nil)
+	if err != nil {
+		return fmt.Errorf("NetBird API: DeleteNetworkRouter: %w", err)
+	}
+	return nil
+}
+
+func networkRouterBody(router data.NetworkRouter) map[string]interface{} {
+	body := map[string]interface{}{
+		"metric":     router.Metric,
+		"masquerade": router.Masquerade,
+		"enabled":    router.Enabled,
+	}
+
+	if len(router.PeerGroups) > 0 {
+		body["peer_groups"] = router.PeerGroups
+	} else {
+		body["peer"] = router.Peer
+	}
+
+	return body
+}
@@ -35,7 +35,16 @@ func (c Client) UpdatePeer(ctx context.Context, peer data.Peer) error {
 	body := map[string]interface{}{
 		"name":                     peer.Name,
 		"ssh_enabled":              peer.SSHEnabled,
-		"login_expiration_enabled": !peer.ExpirationDisabled,
+		"login_expiration_enabled": peer.LoginExpirationEnabled,
+	}
+
+	if peer.InactivityExpirationEnabled != nil {
+		body["inactivity_expiration_enabled"] = *peer.InactivityExpirationEnabled
+	}
+
+	// Only sent if known, NetBird rejects it unless peer approval is available
+	if peer.ApprovalRequired != nil {
+		body["approval_required"] = *peer.ApprovalRequired
 	}
 
 	_, err := c.doRequest(ctx, "PUT", "peers/"+peer.ID, body)
@@ -45,3 +54,18 @@ func (c Client) UpdatePeer(ctx context.Context, peer data.Peer) error {
 
 	return nil
 }
+
+// DeletePeer deletes a single NetBird peer
+func (c Client) DeletePeer(ctx context.Context, peer data.Peer) error {
+	if c.DryRun {
+		slog.Info("DryRun==True")
+		return nil
+	}
+
+	_, err := c.doRequest(ctx, "DELETE", "peers/"+peer.ID, nil)
+	if err != nil {
+		return fmt.Errorf("NetBird API: DeletePeer: %w", err)
+	}
+
+	return nil
+}
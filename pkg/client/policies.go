@@ -36,27 +36,7 @@ func (c Client) UpdatePolicy(ctx context.Context, policy data.Policy) error {
 		return nil
 	}
 
-	body := map[string]interface{}{
-		"name":                  policy.Name,
-		"description":           policy.Description,
-		"enabled":               policy.Enabled,
-		"source_posture_checks": policy.SourcePostureChecks,
-		"rules": []map[string]interface{}{
-			{
-				"name":          policy.Name,
-				"description":   policy.Description,
-				"enabled":       policy.Enabled,
-				"action":        policy.Action,
-				"bidirectional": policy.Bidirectional,
-				"protocol":      policy.Protocol,
-				"ports":         policy.Ports,
-				"sources":       policy.Sources,
-				"destinations":  policy.Destinations,
-			},
-		},
-	}
-
-	_, err := c.doRequest(ctx, "PUT", "policies/"+policy.ID, body)
+	_, err := c.doRequest(ctx, "PUT", "policies/"+policy.ID, policyBody(policy))
 	if err != nil {
 		return fmt.Errorf("NetBird API: UpdatePolicy: %w", err)
 	}
@@ -70,27 +50,7 @@ func (c Client) CreatePolicy(ctx context.Context, policy data.Policy) error {
 		return nil
 	}
 
-	body := map[string]interface{}{
-		"name":                  policy.Name,
-		"description":           policy.Description,
-		"enabled":               policy.Enabled,
-		"source_posture_checks": policy.SourcePostureChecks,
-		"rules": []map[string]interface{}{
-			{
-				"name":          policy.Name,
-				"description":   policy.Description,
-				"enabled":       policy.Enabled,
-				"action":        policy.Action,
-				"bidirectional": policy.Bidirectional,
-				"protocol":      policy.Protocol,
-				"ports":         policy.Ports,
-				"sources":       policy.Sources,
-				"destinations":  policy.Destinations,
-			},
-		},
-	}
-
-	_, err := c.doRequest(ctx, "POST", "policies", body)
+	_, err := c.doRequest(ctx, "POST", "policies", policyBody(policy))
 	if err != nil {
 		return fmt.Errorf("NetBird API: CreatePolicy: %w", err)
 	}
@@ -110,3 +70,33 @@ func (c Client) DeletePolicy(ctx context.Context, policy data.Policy) error {
 	}
 	return nil
 }
+
+func policyBody(policy data.Policy) map[string]interface{} {
+	rule := map[string]interface{}{
+		"name":          policy.Name,
+		"description":   policy.Description,
+		"enabled":       policy.Enabled,
+		"action":        policy.Action,
+		"bidirectional": policy.Bidirectional,
+		"protocol":      policy.Protocol,
+		"ports":         policy.Ports,
+		"sources":       policy.Sources,
+	}
+
+	if policy.DestinationResource != "" {
+		rule["destinationResource"] = data.PolicyResource{
+			ID:   policy.DestinationResource,
+			Type: policy.DestinationResourceType,
+		}
+	} else {
+		rule["destinations"] = policy.Destinations
+	}
+
+	return map[string]interface{}{
+		"name":                  policy.Name,
+		"description":           policy.Description,
+		"enabled":               policy.Enabled,
+		"source_posture_checks": policy.SourcePostureChecks,
+		"rules":                 []map[string]interface{}{rule},
+	}
+}
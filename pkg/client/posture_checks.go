@@ -32,12 +32,7 @@ func (c Client) UpdatePostureCheck(ctx context.Context, postureCheck data.Postur
 		return nil
 	}
 
-	body, err := json.Marshal(postureCheck)
-	if err != nil {
-		return err
-	}
-
-	_, err = c.doRequest(ctx, "PUT", "posture-checks/"+postureCheck.ID, body)
+	_, err := c.doRequest(ctx, "PUT", "posture-checks/"+postureCheck.ID, postureCheckBody(postureCheck))
 	if err != nil {
 		return fmt.Errorf("NetBird API: UpdatePostureCheck: %w", err)
 	}
@@ -51,12 +46,7 @@ func (c Client) CreatePostureCheck(ctx context.Context, postureCheck data.Postur
 		return postureCheck, nil
 	}
 
-	body, err := json.Marshal(postureCheck)
-	if err != nil {
-		return data.PostureCheck{}, err
-	}
-
-	respBytes, err := c.doRequest(ctx, "POST", "posture-checks", body)
+	respBytes, err := c.doRequest(ctx, "POST", "posture-checks", postureCheckBody(postureCheck))
 	if err != nil {
 		return data.PostureCheck{}, fmt.Errorf("NetBird API: CreatePostureCheck: %w", err)
 	}
@@ -84,3 +74,11 @@ func (c Client) DeletePostureCheck(ctx context.Context, postureCheck data.Postur
 	}
 	return nil
 }
+
+func postureCheckBody(postureCheck data.PostureCheck) map[string]interface{} {
+	return map[string]interface{}{
+		"name":        postureCheck.Name,
+		"description": postureCheck.Description,
+		"checks":      postureCheck.Checks,
+	}
+}
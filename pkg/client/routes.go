@@ -32,29 +32,7 @@ func (c Client) UpdateNetworkRoute(ctx context.Context, route data.NetworkRoute)
 		return nil
 	}
 
-	body := map[string]interface{}{
-		"description": route.Description,
-		"network_id":  route.NetworkID,
-		"enabled":     route.Enabled,
-		"metric":      route.Metric,
-		"masquerade":  route.Masquerade,
-		"groups":      route.Groups,
-		"keep_route":  route.KeepRoute,
-	}
-
-	if len(route.Domains) > 0 {
-		body["domains"] = route.Domains
-	} else {
-		body["network"] = route.Network
-	}
-
-	if len(route.PeerGroups) > 0 {
-		body["peer_groups"] = route.PeerGroups
-	} else {
-		body["peer"] = route.Peer
-	}
-
-	_, err := c.doRequest(ctx, "PUT", "routes/"+route.ID, body)
+	_, err := c.doRequest(ctx, "PUT", "routes/"+route.ID, networkRouteBody(route))
 	if err != nil {
 		return fmt.Errorf("NetBird API: UpdateNetworkRoute: %w", err)
 	}
@@ -68,29 +46,7 @@ func (c Client) CreateNetworkRoute(ctx context.Context, route data.NetworkRoute)
 		return nil
 	}
 
-	body := map[string]interface{}{
-		"description": route.Description,
-		"network_id":  route.NetworkID,
-		"enabled":     route.Enabled,
-		"metric":      route.Metric,
-		"masquerade":  route.Masquerade,
-		"groups":      route.Groups,
-		"keep_route":  route.KeepRoute,
-	}
-
-	if len(route.Domains) > 0 {
-		body["domains"] = route.Domains
-	} else {
-		body["network"] = route.Network
-	}
-
-	if len(route.PeerGroups) > 0 {
-		body["peer_groups"] = route.PeerGroups
-	} else {
-		body["peer"] = route.Peer
-	}
-
-	_, err := c.doRequest(ctx, "POST", "routes", body)
+	_, err := c.doRequest(ctx, "POST", "routes", networkRouteBody(route))
 	if err != nil {
 		return fmt.Errorf("NetBird API: CreateNetworkRoute: %w", err)
 	}
@@ -110,3 +66,34 @@ func (c Client) DeleteNetworkRoute(ctx context.Context, route data.NetworkRoute)
 	}
 	return nil
 }
+
+func networkRouteBody(route data.NetworkRoute) map[string]interface{} {
+	body := map[string]interface{}{
+		"description":     route.Description,
+		"network_id":      route.NetworkID,
+		"enabled":         route.Enabled,
+		"metric":          route.Metric,
+		"masquerade":      route.Masquerade,
+		"groups":          route.Groups,
+		"keep_route":      route.KeepRoute,
+		"skip_auto_apply": route.SkipAutoApply,
+	}
+
+	if len(route.Domains) > 0 {
+		body["domains"] = route.Domains
+	} else {
+		body["network"] = route.Network
+	}
+
+	if len(route.PeerGroups) > 0 {
+		body["peer_groups"] = route.PeerGroups
+	} else {
+		body["peer"] = route.Peer
+	}
+
+	if len(route.AccessControlGroups) > 0 {
+		body["access_control_groups"] = route.AccessControlGroups
+	}
+
+	return body
+}
@@ -0,0 +1,91 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+
+	"github.com/mrsool/netbird-gitops/pkg/data"
+)
+
+// ListSetupKeys lists all NetBird setup keys
+func (c Client) ListSetupKeys(ctx context.Context) ([]data.SetupKey, error) {
+	respBytes, err := c.doRequest(ctx, "GET", "setup-keys", nil)
+	if err != nil {
+		return nil, fmt.Errorf("NetBird API: ListSetupKeys: %w", err)
+	}
+	var ret []data.SetupKey
+
+	err = json.Unmarshal(respBytes, &ret)
+	if err != nil {
+		return nil, fmt.Errorf("NetBird API: ListSetupKeys: %w", err)
+	}
+
+	return ret, nil
+}
+
+// CreateSetupKey creates a NetBird setup key, the returned key holds the plain
+// text key material
+func (c Client) CreateSetupKey(ctx context.Context, setupKey data.SetupKey) (data.SetupKey, error) {
+	if c.DryRun {
+		setupKey.ID = setupKey.Name
+		return setupKey, nil
+	}
+
+	body := map[string]interface{}{
+		"name":        setupKey.Name,
+		"type":        setupKey.GetType(),
+		"expires_in":  int(setupKey.ExpiresIn.Seconds()),
+		"auto_groups": setupKey.AutoGroups,
+		"usage_limit": setupKey.UsageLimit,
+		"ephemeral":   setupKey.Ephemeral,
+	}
+
+	respBytes, err := c.doSensitiveRequest(ctx, "POST", "setup-keys", body)
+	if err != nil {
+		return data.SetupKey{}, fmt.Errorf("NetBird API: CreateSetupKey: %w", err)
+	}
+
+	var ret data.SetupKey
+
+	err = json.Unmarshal(respBytes, &ret)
+	if err != nil {
+		return ret, fmt.Errorf("NetBird API: CreateSetupKey: %w", err)
+	}
+
+	return ret, nil
+}
+
+// UpdateSetupKey updates revocation and auto groups of a NetBird setup key
+func (c Client) UpdateSetupKey(ctx context.Context, setupKey data.SetupKey) error {
+	if c.DryRun {
+		slog.Info("DryRun==True")
+		return nil
+	}
+
+	body := map[string]interface{}{
+		"revoked":     setupKey.Revoked,
+		"auto_groups": setupKey.AutoGroups,
+	}
+
+	_, err := c.doRequest(ctx, "PUT", "setup-keys/"+setupKey.ID, body)
+	if err != nil {
+		return fmt.Errorf("NetBird API: UpdateSetupKey: %w", err)
+	}
+	return nil
+}
+
+// DeleteSetupKey deletes a NetBird setup key
+func (c Client) DeleteSetupKey(ctx context.Context, setupKey data.SetupKey) error {
+	if c.DryRun {
+		slog.Info("DryRun==True")
+		return nil
+	}
+
+	_, err := c.doRequest(ctx, "DELETE", "setup-keys/"+setupKey.ID, nil)
+	if err != nil {
+		return fmt.Errorf("NetBird API: DeleteSetupKey: %w", err)
+	}
+	return nil
+}
@@ -44,3 +44,126 @@ func (c Client) UpdateUser(ctx context.Context, user data.User) error {
 	}
 	return nil
 }
+
+// CreateUser creates a NetBird user, or a service user if user.ServiceUser
+func (c Client) CreateUser(ctx context.Context, user data.User) (data.User, error) {
+	if c.DryRun {
+		user.ID = user.Name
+		return user, nil
+	}
+
+	body := map[string]interface{}{
+		"name":            user.Name,
+		"role":            user.GetRole(),
+		"auto_groups":     user.Groups,
+		"is_service_user": user.ServiceUser,
+	}
+	if user.Email != "" {
+		body["email"] = user.Email
+	}
+
+	respBytes, err := c.doRequest(ctx, "POST", "users", body)
+	if err != nil {
+		return data.User{}, fmt.Errorf("NetBird API: CreateUser: %w", err)
+	}
+
+	var ret data.User
+
+	err = json.Unmarshal(respBytes, &ret)
+	if err != nil {
+		return ret, fmt.Errorf("NetBird API: CreateUser: %w", err)
+	}
+
+	return ret, nil
+}
+
+// DeleteUser deletes a single NetBird user
+func (c Client) DeleteUser(ctx context.Context, user data.User) error {
+	if c.DryRun {
+		slog.Info("DryRun==True")
+		return nil
+	}
+
+	_, err := c.doRequest(ctx, "DELETE", "users/"+user.ID, nil)
+	if err != nil {
+		return fmt.Errorf("NetBird API: DeleteUser: %w", err)
+	}
+	return nil
+}
+
+// ListTokens lists personal access tokens of a NetBird user
+func (c Client) ListTokens(ctx context.Context, user data.User) ([]data.PersonalAccessToken, error) {
+	respBytes, err := c.doRequest(ctx, "GET", "users/"+user.ID+"/tokens", nil)
+	if err != nil {
+		return nil, fmt.Errorf("NetBird API: ListTokens: %w", err)
+	}
+	var ret []data.PersonalAccessToken
+
+	err = json.Unmarshal(respBytes, &ret)
+	if err != nil {
+		return nil, fmt.Errorf("NetBird API: ListTokens: %w", err)
+	}
+
+	return ret, nil
+}
+
+// CreateToken creates a personal access token for a NetBird user, returning
+// the token metadata and its plain text value
+func (c Client) CreateToken(ctx context.Context, user data.User, token data.PersonalAccessToken) (data.PersonalAccessToken, string, error) {
+	if c.DryRun {
+		token.ID = token.Name
+		return token, "", nil
+	}
+
+	body := map[string]interface{}{
+		"name":       token.Name,
+		"expires_in": token.ExpiresInDays(),
+	}
+
+	respBytes, err := c.doSensitiveRequest(ctx, "POST", "users/"+user.ID+"/tokens", body)
+	if err != nil {
+		return data.PersonalAccessToken{}, "", fmt.Errorf("NetBird API: CreateToken: %w", err)
+	}
+
+	var ret struct {
+		PlainToken string                   `json:"plain_token"`
+		Token      data.PersonalAccessToken `json:"personal_access_token"`
+	}
+
+	err = json.Unmarshal(respBytes, &ret)
+	if err != nil {
+		return data.PersonalAccessToken{}, "", fmt.Errorf("NetBird API: CreateToken: %w", err)
+	}
+
+	return ret.Token, ret.PlainToken, nil
+}
+
+// DeleteToken deletes a personal access token of a NetBird user
+func (c Client) DeleteToken(ctx context.Context, user data.User, token data.PersonalAccessToken) error {
+	if c.DryRun {
+		slog.Info("DryRun==True")
+		return nil
+	}
+
+	_, err := c.doRequest(ctx, "DELETE", "users/"+user.ID+"/tokens/"+token.ID, nil)
+	if err != nil {
+		return fmt.Errorf("NetBird API: DeleteToken: %w", err)
+	}
+	return nil
+}
+
+// GetCurrentUser returns the NetBird user owning the API token
+func (c Client) GetCurrentUser(ctx context.Context) (data.User, error) {
+	respBytes, err := c.doRequest(ctx, "GET", "users/current", nil)
+	if err != nil {
+		return data.User{}, fmt.Errorf("NetBird API: GetCurrentUser: %w", err)
+	}
+	var ret data.User
+
+	err = json.Unmarshal(respBytes, &ret)
+	if err != nil {
+		return data.User{}, fmt.Errorf("NetBird API: GetCurrentUser: %w", err)
+	}
+
+	return ret, nil
+}
@@ -0,0 +1,39 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/mrsool/netbird-gitops/pkg/data"
+	"github.com/nikoksr/notify"
+)
+
+func (c Controller) syncAccountSettings(ctx context.Context, cfg *data.CombinedConfig) error {
+	if cfg.AccountSettings == nil {
+		slog.Info("No account settings defined in Git configuration, skipping account settings sync")
+		return nil
+	}
+
+	accounts, err := c.netbirdClient.ListAccounts(ctx)
+	if err != nil {
+		return err
+	}
+	if len(accounts) != 1 {
+		return errors.New("API token should have access to 1 account exactly")
+	}
+	account := accounts[0]
+
+	diff := cfg.AccountSettings.Diff(account.Settings)
+	if len(diff) == 0 {
+		slog.Debug("Account settings match")
+		return nil
+	}
+
+	slog.Warn("Updating account settings", "changes", diff)
+	notify.Send(ctx, "", fmt.Sprintf("Updating account settings: %s", strings.Join(diff, ", ")))
+	cfg.AccountSettings.Apply(account.RawSettings)
+	return c.netbirdClient.UpdateAccount(ctx, account)
+}
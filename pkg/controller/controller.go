@@ -12,6 +12,7 @@ import (
 
 	"github.com/mrsool/netbird-gitops/pkg/client"
 	"github.com/mrsool/netbird-gitops/pkg/data"
+	"github.com/mrsool/netbird-gitops/pkg/secrets"
 	"github.com/go-git/go-git/v5"
 	"github.com/go-git/go-git/v5/plumbing"
 	"github.com/go-git/go-git/v5/plumbing/transport"
@@ -23,26 +24,45 @@ const localRepoPath = "/tmp/netbird-gitops"
 
 // Controller main logic controller
 type Controller struct {
-	netbirdClient client.Client
+	netbirdClient  client.Client
+	self           data.User
+	pendingInvites map[string]*pendingInvite
+	blockedAt      map[string]time.Time
+	keyCreated     map[string]time.Time
+	keyUnpublished map[string]bool
+	lastWriteBack  string
+	validity       map[string]bool
+	unapplied      map[string]bool
+	expiryNotified map[string]bool
 	*Options
 }
 
 // Options controller settings
 type Options struct {
-	GitRepoURL      string
-	GitRelativePath string
-	GitBranch       string
-	GitAuth         transport.AuthMethod
-	NetBirdToken    string
-	NetBirdAPI      string
-	SyncOnceAndExit bool
-	PollFrequency   time.Duration
+	GitRepoURL         string
+	GitRelativePath    string
+	GitBranch          string
+	GitAuth            transport.AuthMethod
+	NetBirdToken       string
+	NetBirdAPI         string
+	SyncOnceAndExit    bool
+	PollFrequency      time.Duration
+	SecretSink         secrets.Sink
+	WriteBackBranch    string
+	WriteBackPatchPath string
 }
 
 // NewController init
 func NewController(opts Options) *Controller {
 	return &Controller{
-		Options: &opts,
+		Options:        &opts,
+		pendingInvites: make(map[string]*pendingInvite),
+		blockedAt:      make(map[string]time.Time),
+		keyCreated:     make(map[string]time.Time),
+		keyUnpublished: make(map[string]bool),
+		validity:       make(map[string]bool),
+		unapplied:      make(map[string]bool),
+		expiryNotified: make(map[string]bool),
 	}
 }
 
@@ -50,6 +70,13 @@ func NewController(opts Options) *Controller {
 func (c *Controller) Start(ctx context.Context) error {
 	// Init NetBird Client
 	c.netbirdClient = *client.NewClient(c.NetBirdAPI, c.NetBirdToken, true)
+	// Identify API token owner to protect it from being locked out
+	self, err := c.netbirdClient.GetCurrentUser(ctx)
+	if err != nil {
+		return fmt.Errorf("Failed to get API token user: %w", err)
+	}
+	c.self = self
+	slog.Info("Identified API token user", "id", self.ID, "email", self.Email, "name", self.Name, "role", self.Role, "service_user", self.ServiceUser)
 	// Clone initial repository and handle sync logic
 	slog.Info("Cloning repository")
 	os.RemoveAll(localRepoPath)
@@ -74,9 +101,26 @@ func (c *Controller) Start(ctx context.Context) error {
 
 	}
 
-	if err := c.doSync(ctx, cfg, !c.SyncOnceAndExit && cfg.Config.AutoSync == "manual"); err != nil {
+	now := time.Now()
+	c.checkValidity(ctx, cfg, now)
+	cfg.ApplyValidity(now)
+
+	initialDryRun := !c.SyncOnceAndExit && cfg.Config.AutoSync == "manual"
+	if err := c.doSync(ctx, cfg, initialDryRun); err != nil {
 		slog.Error("Failed to sync", "err", err)
 		notify.Send(ctx, "Sync failed", fmt.Sprintf("Failed to do initial sync due to error: %s", err.Error()))
+	} else if !initialDryRun {
+		clear(c.unapplied)
+	}
+
+	if err := c.rotateSetupKeys(ctx, cfg); err != nil {
+		slog.Error("Failed to rotate setup keys", "err", err)
+		notify.Send(ctx, "Setup key rotation failed", fmt.Sprintf("Failed to rotate setup keys due to error: %s", err.Error()))
+	}
+
+	if err := c.writeBack(ctx, cfg); err != nil {
+		slog.Error("Failed to write back proposals", "err", err)
+		notify.Send(ctx, "Write back failed", fmt.Sprintf("Failed to write back proposals due to error: %s", err.Error()))
 	}
 
 	if c.SyncOnceAndExit {
@@ -178,9 +222,35 @@ func (c *Controller) Start(ctx context.Context) error {
 			dryRun = true
 		}
 
+		now := time.Now()
+		c.checkValidity(ctx, cfg, now)
+		cfg.ApplyValidity(now)
+
 		if err := c.doSync(ctx, cfg, dryRun); err != nil {
 			notify.Send(ctx, "Sync failed", fmt.Sprintf("Failed to sync %s/%s with error: %s", c.GitRepoURL, c.GitRelativePath, err.Error()))
 			slog.Error("Failed to sync", "err", err)
+		} else if !dryRun {
+			clear(c.unapplied)
+		}
+
+		// Time-bound entries change without git changes, apply only those so
+		// other drift is left to the next sync
+		if dryRun && autoApply(cfg) {
+			if err := c.applyValidity(ctx, cfg, now); err != nil {
+				notify.Send(ctx, "Sync failed", fmt.Sprintf("Failed to apply time-bound changes with error: %s", err.Error()))
+				slog.Error("Failed to apply time-bound changes", "err", err)
+			}
+		}
+
+		// Rotation is time based, so it is applied even if git is unchanged
+		if err := c.rotateSetupKeys(ctx, cfg); err != nil {
+			notify.Send(ctx, "Setup key rotation failed", fmt.Sprintf("Failed to rotate setup keys with error: %s", err.Error()))
+			slog.Error("Failed to rotate setup keys", "err", err)
+		}
+
+		if err := c.writeBack(ctx, cfg); err != nil {
+			notify.Send(ctx, "Write back failed", fmt.Sprintf("Failed to write back proposals with error: %s", err.Error()))
+			slog.Error("Failed to write back proposals", "err", err)
 		}
 
 		latestHead = curHead
@@ -188,6 +258,12 @@ func (c *Controller) Start(ctx context.Context) error {
 	}
 }
 
+// autoApply returns true if autoSync mode allows applying changes without
+// a manual sync
+func autoApply(cfg *data.CombinedConfig) bool {
+	return cfg.Config.AutoSync == "update" || cfg.Config.AutoSync == "enforce"
+}
+
 func (c *Controller) getCombinedConfig() (*data.CombinedConfig, error) {
 	localPath := path.Join(localRepoPath, c.GitRelativePath)
 	files, err := os.ReadDir(localPath)
@@ -220,5 +296,17 @@ func (c *Controller) getCombinedConfig() (*data.CombinedConfig, error) {
 		return nil, err
 	}
 
+	// Exit nodes are plain default routes, expand them before validation so
+	// they are checked together with network_routes
+	cfg.ExpandExitNodes()
+
+	warnings, err := cfg.Validate()
+	for _, w := range warnings {
+		slog.Warn("Config validation", "warning", w)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+
 	return cfg, nil
 }
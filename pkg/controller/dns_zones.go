@@ -0,0 +1,123 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/mrsool/netbird-gitops/pkg/data"
+	"github.com/mrsool/netbird-gitops/pkg/util"
+	"github.com/nikoksr/notify"
+)
+
+func (c Controller) syncDNSZones(ctx context.Context, cfg *data.CombinedConfig, groupNameID map[string]string) error {
+	// If no DNS zones are defined in Git config, skip DNS zone sync entirely
+	if len(cfg.DNSZones) == 0 {
+		slog.Info("No DNS zones defined in Git configuration, skipping DNS zone sync")
+		return nil
+	}
+
+	zones, err := c.netbirdClient.ListDNSZones(ctx)
+	if err != nil {
+		return err
+	}
+
+	nbRevMap := util.SliceToMap(zones, func(z data.DNSZone) string { return data.NormalizeDomain(z.Domain) })
+	gitRevMap := util.SliceToMap(cfg.DNSZones, func(z data.DNSZone) string { return data.NormalizeDomain(z.Domain) })
+
+	for k, v := range gitRevMap {
+		gitZone := v
+		gitZone.Domain = k
+		gitZone.DistributionGroups = util.Map(v.DistributionGroups, func(g string) string { return groupNameID[g] })
+
+		nbz, ok := nbRevMap[k]
+		created := !ok
+		if !ok {
+			slog.Warn("Creating DNS zone", "domain", v.Domain)
+			notify.Send(ctx, "", fmt.Sprintf("Creating DNS zone %s with config: %+v", v.Domain, v))
+			nbz, err = c.netbirdClient.CreateDNSZone(ctx, gitZone)
+			if err != nil {
+				return err
+			}
+		} else if !nbz.Equals(gitZone) {
+			slog.Warn("Updating DNS zone", "domain", v.Domain)
+			notify.Send(ctx, "", fmt.Sprintf("Updating DNS zone %s with config: %+v", v.Domain, v))
+			gitZone.ID = nbz.ID
+			err = c.netbirdClient.UpdateDNSZone(ctx, gitZone)
+			if err != nil {
+				return err
+			}
+		} else {
+			slog.Debug("DNS zone matches", "domain", v.Domain)
+		}
+
+		err = c.syncDNSRecords(ctx, nbz, v, created)
+		if err != nil {
+			return err
+		}
+	}
+
+	for k, v := range nbRevMap {
+		if _, ok := gitRevMap[k]; !ok {
+			slog.Warn("Deleting DNS zone", "domain", v.Domain)
+			notify.Send(ctx, "", fmt.Sprintf("Deleting DNS zone %s as it doesn't exist in Git", v.Domain))
+			err = c.netbirdClient.DeleteDNSZone(ctx, v)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (c Controller) syncDNSRecords(ctx context.Context, nbz, zone data.DNSZone, created bool) error {
+	var records []data.DNSRecord
+	if !created {
+		var err error
+		records, err = c.netbirdClient.ListDNSRecords(ctx, nbz)
+		if err != nil {
+			return err
+		}
+	}
+
+	nbRevMap := util.SliceToMap(records, func(r data.DNSRecord) string { return r.Key() })
+	gitRevMap := util.SliceToMap(zone.Records, func(r data.DNSRecord) string { return r.Key() })
+
+	for k, v := range gitRevMap {
+		nbr, ok := nbRevMap[k]
+		if !ok {
+			slog.Warn("Creating DNS record", "zone", zone.Domain, "record", k)
+			notify.Send(ctx, "", fmt.Sprintf("Creating DNS record %s in zone %s", k, zone.Domain))
+			err := c.netbirdClient.CreateDNSRecord(ctx, nbz, v)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		if nbr.TTL == v.GetTTL() {
+			slog.Debug("DNS record matches", "zone", zone.Domain, "record", k)
+			continue
+		}
+		slog.Warn("Updating DNS record", "zone", zone.Domain, "record", k, "old_ttl", nbr.TTL, "new_ttl", v.GetTTL())
+		notify.Send(ctx, "", fmt.Sprintf("Updating DNS record %s in zone %s: ttl: %d -> %d", k, zone.Domain, nbr.TTL, v.GetTTL()))
+		v.ID = nbr.ID
+		err := c.netbirdClient.UpdateDNSRecord(ctx, nbz, v)
+		if err != nil {
+			return err
+		}
+	}
+
+	for k, v := range nbRevMap {
+		if _, ok := gitRevMap[k]; !ok {
+			slog.Warn("Deleting DNS record", "zone", zone.Domain, "record", k)
+			notify.Send(ctx, "", fmt.Sprintf("Deleting DNS record %s in zone %s as it doesn't exist in Git", k, zone.Domain))
+			err := c.netbirdClient.DeleteDNSRecord(ctx, nbz, v)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
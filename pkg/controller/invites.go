@@ -0,0 +1,72 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/mrsool/netbird-gitops/pkg/data"
+	"github.com/mrsool/netbird-gitops/pkg/util"
+	"github.com/nikoksr/notify"
+)
+
+// pendingInvite tracks a user invited but not yet onboarded
+type pendingInvite struct {
+	since    time.Time
+	reported bool
+}
+
+// inviteUser invites a user defined in Git but missing from NetBird
+func (c Controller) inviteUser(ctx context.Context, gitUser data.User, groupNameID map[string]string) error {
+	slog.Warn("Inviting user", "email", gitUser.Email, "groups", gitUser.Groups, "role", gitUser.GetRole())
+	notify.Send(ctx, "", fmt.Sprintf("Inviting user %s with config: %+v", gitUser.Email, gitUser))
+
+	_, err := c.netbirdClient.CreateUser(ctx, data.User{
+		Email:  gitUser.Email,
+		Name:   gitUser.Email,
+		Role:   gitUser.GetRole(),
+		Groups: util.Map(gitUser.Groups, func(g string) string { return groupNameID[g] }),
+	})
+	if err != nil {
+		return err
+	}
+
+	if !c.netbirdClient.DryRun {
+		c.pendingInvites[strings.ToLower(gitUser.Email)] = &pendingInvite{since: time.Now()}
+	}
+	return nil
+}
+
+// trackPendingInvites records users still pending onboarding and reports once
+// those pending for longer than config.inviteTimeout
+func (c Controller) trackPendingInvites(ctx context.Context, cfg *data.CombinedConfig, users []data.User) {
+	pending := make(map[string]bool)
+	for _, u := range users {
+		if u.ServiceUser || u.Email == "" || u.Status != data.UserStatusInvited {
+			continue
+		}
+		email := strings.ToLower(u.Email)
+		pending[email] = true
+
+		invite, ok := c.pendingInvites[email]
+		if !ok {
+			invite = &pendingInvite{since: time.Now()}
+			c.pendingInvites[email] = invite
+		}
+
+		if cfg.Config.InviteTimeout <= 0 || invite.reported || time.Since(invite.since) < cfg.Config.InviteTimeout {
+			continue
+		}
+		slog.Warn("User invited but not onboarded", "email", u.Email, "since", invite.since)
+		notify.Send(ctx, "", fmt.Sprintf("User %s was invited at %s but has not onboarded yet", u.Email, invite.since.Format(time.RFC3339)))
+		invite.reported = true
+	}
+
+	for email := range c.pendingInvites {
+		if !pending[email] {
+			delete(c.pendingInvites, email)
+		}
+	}
+}
@@ -0,0 +1,209 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/mrsool/netbird-gitops/pkg/data"
+	"github.com/mrsool/netbird-gitops/pkg/util"
+	"github.com/nikoksr/notify"
+)
+
+// syncNetworks creates and updates networks with their resources and routers,
+// returns resources keyed by network/resource for policy destinations
+func (c Controller) syncNetworks(ctx context.Context, cfg *data.CombinedConfig, groupNameID map[string]string) (map[string]data.NetworkResource, error) {
+	resourceRefs := make(map[string]data.NetworkResource)
+	// If no networks are defined in Git config, skip network sync entirely
+	if len(cfg.Networks) == 0 {
+		slog.Info("No networks defined in Git configuration, skipping network sync")
+		return resourceRefs, nil
+	}
+
+	networks, err := c.netbirdClient.ListNetworks(ctx)
+	if err != nil {
+		return nil, err
+	}
+	nbRevMap := util.SliceToMap(networks, func(n data.Network) string { return n.Name })
+
+	for _, v := range cfg.Networks {
+		nbn, ok := nbRevMap[v.Name]
+		created := !ok
+		if !ok {
+			slog.Warn("Creating network", "name", v.Name)
+			notify.Send(ctx, "", fmt.Sprintf("Creating network %s", v.Name))
+			nbn, err = c.netbirdClient.CreateNetwork(ctx, v)
+			if err != nil {
+				return nil, err
+			}
+		} else if nbn.Description != v.Description {
+			slog.Warn("Updating network", "name", v.Name, "old_description", nbn.Description, "new_description", v.Description)
+			notify.Send(ctx, "", fmt.Sprintf("Updating network %s description to %q", v.Name, v.Description))
+			nbn.Description = v.Description
+			err = c.netbirdClient.UpdateNetwork(ctx, nbn)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		resources, err := c.syncNetworkResources(ctx, nbn, v, created, groupNameID)
+		if err != nil {
+			return nil, err
+		}
+		for k, r := range resources {
+			resourceRefs[data.ResourceRef(v.Name, k)] = r
+		}
+
+		err = c.syncNetworkRouters(ctx, nbn, v, created, groupNameID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return resourceRefs, nil
+}
+
+func (c Controller) syncNetworkResources(ctx context.Context, nbn, network data.Network, created bool, groupNameID map[string]string) (map[string]data.NetworkResource, error) {
+	var resources []data.NetworkResource
+	if !created {
+		var err error
+		resources, err = c.netbirdClient.ListNetworkResources(ctx, nbn)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	nbRevMap := util.SliceToMap(resources, func(r data.NetworkResource) string { return r.Name })
+	ret := make(map[string]data.NetworkResource)
+	for _, v := range network.Resources {
+		gitResource := v
+		gitResource.Groups = util.Map(v.GroupNames, func(g string) data.Group { return data.Group{ID: groupNameID[g], Name: g} })
+
+		nbr, ok := nbRevMap[v.Name]
+		if !ok {
+			slog.Warn("Creating network resource", "network", network.Name, "name", v.Name)
+			notify.Send(ctx, "", fmt.Sprintf("Creating network resource %s with config: %+v", data.ResourceRef(network.Name, v.Name), v))
+			nbr, err := c.netbirdClient.CreateNetworkResource(ctx, nbn, gitResource)
+			if err != nil {
+				return nil, err
+			}
+			ret[v.Name] = nbr
+			continue
+		}
+
+		ret[v.Name] = nbr
+		if nbr.Equals(gitResource) {
+			slog.Debug("Network resource matches", "network", network.Name, "name", v.Name)
+			continue
+		}
+		slog.Warn("Updating network resource", "network", network.Name, "name", v.Name)
+		notify.Send(ctx, "", fmt.Sprintf("Updating network resource %s with config: %+v", data.ResourceRef(network.Name, v.Name), v))
+		gitResource.ID = nbr.ID
+		err := c.netbirdClient.UpdateNetworkResource(ctx, nbn, gitResource)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return ret, nil
+}
+
+func (c Controller) syncNetworkRouters(ctx context.Context, nbn, network data.Network, created bool, groupNameID map[string]string) error {
+	var routers []data.NetworkRouter
+	if !created {
+		var err error
+		routers, err = c.netbirdClient.ListNetworkRouters(ctx, nbn)
+		if err != nil {
+			return err
+		}
+	}
+
+	gitRouters := util.Map(network.Routers, func(r data.NetworkRouter) data.NetworkRouter {
+		r.PeerGroups = util.Map(r.PeerGroups, func(g string) string { return groupNameID[g] })
+		return r
+	})
+	nbRevMap := util.SliceToMap(routers, func(r data.NetworkRouter) string { return r.Key() })
+	gitRevMap := util.SliceToMap(gitRouters, func(r data.NetworkRouter) string { return r.Key() })
+
+	for k, v := range gitRevMap {
+		nbr, ok := nbRevMap[k]
+		if !ok {
+			slog.Warn("Creating network router", "network", network.Name, "router", k)
+			notify.Send(ctx, "", fmt.Sprintf("Creating router %s in network %s with config: %+v", k, network.Name, v))
+			err := c.netbirdClient.CreateNetworkRouter(ctx, nbn, v)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		if nbr.Equals(v) {
+			slog.Debug("Network router matches", "network", network.Name, "router", k)
+			continue
+		}
+		slog.Warn("Updating network router", "network", network.Name, "router", k)
+		notify.Send(ctx, "", fmt.Sprintf("Updating router %s in network %s with config: %+v", k, network.Name, v))
+		v.ID = nbr.ID
+		err := c.netbirdClient.UpdateNetworkRouter(ctx, nbn, v)
+		if err != nil {
+			return err
+		}
+	}
+
+	for k, v := range nbRevMap {
+		if _, ok := gitRevMap[k]; !ok {
+			slog.Warn("Deleting network router", "network", network.Name, "router", k)
+			notify.Send(ctx, "", fmt.Sprintf("Deleting router %s in network %s as it doesn't exist in Git", k, network.Name))
+			err := c.netbirdClient.DeleteNetworkRouter(ctx, nbn, v)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// pruneNetworks deletes resources and networks missing from Git, runs after
+// policies are synced so no policy references them anymore
+func (c Controller) pruneNetworks(ctx context.Context, cfg *data.CombinedConfig) error {
+	if len(cfg.Networks) == 0 {
+		return nil
+	}
+
+	networks, err := c.netbirdClient.ListNetworks(ctx)
+	if err != nil {
+		return err
+	}
+	gitRevMap := util.SliceToMap(cfg.Networks, func(n data.Network) string { return n.Name })
+
+	for _, v := range networks {
+		gitNetwork, ok := gitRevMap[v.Name]
+		if !ok {
+			slog.Warn("Deleting network", "name", v.Name)
+			notify.Send(ctx, "", fmt.Sprintf("Deleting network %s as it doesn't exist in Git", v.Name))
+			err = c.netbirdClient.DeleteNetwork(ctx, v)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		resources, err := c.netbirdClient.ListNetworkResources(ctx, v)
+		if err != nil {
+			return err
+		}
+		gitResources := util.SliceToMap(gitNetwork.Resources, func(r data.NetworkResource) string { return r.Name })
+		for _, r := range resources {
+			if _, ok := gitResources[r.Name]; !ok {
+				slog.Warn("Deleting network resource", "network", v.Name, "name", r.Name)
+				notify.Send(ctx, "", fmt.Sprintf("Deleting network resource %s as it doesn't exist in Git", data.ResourceRef(v.Name, r.Name)))
+				err = c.netbirdClient.DeleteNetworkResource(ctx, v, r)
+				if err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return nil
+}
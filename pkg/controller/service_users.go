@@ -0,0 +1,192 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/mrsool/netbird-gitops/pkg/data"
+	"github.com/mrsool/netbird-gitops/pkg/util"
+	"github.com/nikoksr/notify"
+)
+
+func (c Controller) syncServiceUsers(ctx context.Context, cfg *data.CombinedConfig, groupNameID, groupIDName map[string]string) error {
+	// If no service users are defined in Git config, skip service user sync entirely
+	if len(cfg.ServiceUsers) == 0 {
+		slog.Info("No service users defined in Git configuration, skipping service user sync")
+		return nil
+	}
+
+	users, err := c.netbirdClient.ListUsers(ctx)
+	if err != nil {
+		return err
+	}
+
+	guard := newUserGuard(users, c.self)
+
+	serviceUsers := util.Select(users, func(u data.User) bool { return u.ServiceUser })
+	nbRevMap := util.SliceToMap(serviceUsers, func(u data.User) string { return u.Name })
+	gitRevMap := util.SliceToMap(cfg.ServiceUsers, func(u data.ServiceUser) string { return u.Name })
+
+	for k, v := range gitRevMap {
+		gitUser := data.User{
+			Name:        v.Name,
+			Role:        v.GetRole(),
+			Groups:      util.Map(v.Groups, func(g string) string { return groupNameID[g] }),
+			ServiceUser: true,
+		}
+
+		nbu, ok := nbRevMap[k]
+		created := !ok
+		if !ok {
+			slog.Warn("Creating service user", "name", v.Name)
+			notify.Send(ctx, "", fmt.Sprintf("Creating service user %s with config: %+v", v.Name, v))
+			nbu, err = c.netbirdClient.CreateUser(ctx, gitUser)
+			if err != nil {
+				return err
+			}
+		} else {
+			if gitUser.Role != nbu.Role && guard.allow(ctx, nbu, gitUser.Role, false) != nil {
+				// Keep current role, groups are still synced
+				gitUser.Role = nbu.Role
+			}
+			if util.SortedEqual(util.Map(nbu.Groups, func(g string) string { return groupIDName[g] }), v.Groups) && nbu.Role == gitUser.Role {
+				slog.Debug("Service user matches in NetBird and Git", "name", v.Name)
+			} else {
+				slog.Warn("Updating service user", "name", v.Name,
+					"old_groups", util.Map(nbu.Groups, func(g string) string { return groupIDName[g] }), "new_groups", v.Groups,
+					"old_role", nbu.Role, "new_role", gitUser.Role)
+				notify.Send(ctx, "", fmt.Sprintf("Updating service user %s with config: %+v", v.Name, v))
+				gitUser.ID = nbu.ID
+				err = c.netbirdClient.UpdateUser(ctx, gitUser)
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		err = c.syncTokens(ctx, nbu, v, created)
+		if err != nil {
+			return err
+		}
+	}
+
+	for k, v := range nbRevMap {
+		if _, ok := gitRevMap[k]; !ok {
+			if guard.allow(ctx, v, v.Role, true) != nil {
+				continue
+			}
+			slog.Warn("Deleting service user", "name", v.Name)
+			notify.Send(ctx, "", fmt.Sprintf("Deleting service user %s as it doesn't exist in Git", v.Name))
+			err = c.netbirdClient.DeleteUser(ctx, v)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// syncTokens creates, rotates and deletes personal access tokens of a service
+// user. Tokens are left untouched if none are declared
+func (c Controller) syncTokens(ctx context.Context, user data.User, serviceUser data.ServiceUser, created bool) error {
+	if len(serviceUser.Tokens) == 0 {
+		return nil
+	}
+
+	var tokens []data.PersonalAccessToken
+	if !created {
+		var err error
+		tokens, err = c.netbirdClient.ListTokens(ctx, user)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Manage the latest token per name, older ones are leftovers of rotation
+	latest := make(map[string]data.PersonalAccessToken)
+	var previous []data.PersonalAccessToken
+	for _, t := range tokens {
+		if cur, ok := latest[t.Name]; ok {
+			if cur.ExpirationDate.After(t.ExpirationDate) {
+				previous = append(previous, t)
+				continue
+			}
+			previous = append(previous, cur)
+		}
+		latest[t.Name] = t
+	}
+
+	gitRevMap := util.SliceToMap(serviceUser.Tokens, func(t data.PersonalAccessToken) string { return t.Name })
+	for k, v := range gitRevMap {
+		nbt, ok := latest[k]
+		rotate := ok && time.Until(nbt.ExpirationDate) <= v.RotateBefore
+		if ok && !rotate {
+			slog.Debug("Token is valid", "user", user.Name, "token", v.Name, "expiration", nbt.ExpirationDate)
+			continue
+		}
+
+		if c.SecretSink == nil {
+			slog.Error("No secret sink configured, skipping token creation", "user", user.Name, "token", v.Name)
+			notify.Send(ctx, "", fmt.Sprintf("Cannot create token %s for service user %s: no secret sink configured", v.Name, user.Name))
+			continue
+		}
+
+		if rotate {
+			slog.Warn("Rotating token", "user", user.Name, "token", v.Name, "expiration", nbt.ExpirationDate)
+			notify.Send(ctx, "", fmt.Sprintf("Rotating token %s for service user %s expiring at %s", v.Name, user.Name, nbt.ExpirationDate.Format(time.RFC3339)))
+		} else {
+			slog.Warn("Creating token", "user", user.Name, "token", v.Name)
+			notify.Send(ctx, "", fmt.Sprintf("Creating token %s for service user %s", v.Name, user.Name))
+		}
+
+		token, plainToken, err := c.netbirdClient.CreateToken(ctx, user, v)
+		if err != nil {
+			return err
+		}
+		if c.netbirdClient.DryRun {
+			continue
+		}
+		err = c.SecretSink.Write(ctx, fmt.Sprintf("service-user-%s-%s", user.Name, v.Name), plainToken)
+		if err != nil {
+			// The token value is lost, delete the token so it is recreated
+			slog.Error("Failed to write token, deleting it", "user", user.Name, "token", v.Name, "err", err)
+			if delErr := c.netbirdClient.DeleteToken(ctx, user, token); delErr != nil {
+				return errors.Join(err, delErr)
+			}
+			return err
+		}
+	}
+
+	var stale []data.PersonalAccessToken
+	for k, v := range latest {
+		if _, ok := gitRevMap[k]; !ok {
+			stale = append(stale, v)
+		}
+	}
+	for _, t := range previous {
+		gitToken, ok := gitRevMap[t.Name]
+		switch {
+		case !ok, time.Now().After(t.ExpirationDate):
+			stale = append(stale, t)
+		case gitToken.Overlap > 0 && time.Since(latest[t.Name].CreatedAt) >= gitToken.Overlap:
+			stale = append(stale, t)
+		default:
+			slog.Debug("Keeping rotated token during overlap", "user", user.Name, "token", t.Name, "id", t.ID, "expiration", t.ExpirationDate)
+		}
+	}
+
+	for _, t := range stale {
+		slog.Warn("Deleting token", "user", user.Name, "token", t.Name, "id", t.ID)
+		notify.Send(ctx, "", fmt.Sprintf("Deleting token %s (%s) of service user %s", t.Name, t.ID, user.Name))
+		err := c.netbirdClient.DeleteToken(ctx, user, t)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
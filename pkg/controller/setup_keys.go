@@ -0,0 +1,232 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/mrsool/netbird-gitops/pkg/data"
+	"github.com/mrsool/netbird-gitops/pkg/util"
+	"github.com/nikoksr/notify"
+)
+
+func (c Controller) syncSetupKeys(ctx context.Context, cfg *data.CombinedConfig, groupNameID, groupIDName map[string]string) error {
+	// If no setup keys are defined in Git config, skip setup key sync entirely
+	if len(cfg.SetupKeys) == 0 {
+		slog.Info("No setup keys defined in Git configuration, skipping setup key sync")
+		return nil
+	}
+
+	keys, err := c.netbirdClient.ListSetupKeys(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Rotation leaves multiple keys with the same name, manage the latest one
+	keysRevMap := latestSetupKeys(keys)
+	gitKeysRevMap := util.SliceToMap(cfg.SetupKeys, func(k data.SetupKey) string { return k.Name })
+
+	for k, v := range gitKeysRevMap {
+		gitKey := v
+		gitKey.AutoGroups = util.Map(v.AutoGroups, func(g string) string { return groupNameID[g] })
+
+		nbk, ok := keysRevMap[k]
+		if !ok {
+			if v.Revoked {
+				slog.Debug("Setup key is revoked, skipping creation", "name", v.Name)
+				continue
+			}
+			err = c.createSetupKey(ctx, gitKey)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		if nbk.Type != gitKey.GetType() || nbk.UsageLimit != gitKey.UsageLimit || nbk.Ephemeral != gitKey.Ephemeral {
+			slog.Warn("Setup key type, usage_limit and ephemeral cannot be updated, delete or rename the key to recreate it", "name", v.Name,
+				"old_type", nbk.Type, "new_type", gitKey.GetType(),
+				"old_usage_limit", nbk.UsageLimit, "new_usage_limit", gitKey.UsageLimit,
+				"old_ephemeral", nbk.Ephemeral, "new_ephemeral", gitKey.Ephemeral)
+		}
+
+		nbGroupNames := util.Map(nbk.AutoGroups, func(g string) string { return groupIDName[g] })
+		if nbk.Revoked == v.Revoked && util.SortedEqual(nbGroupNames, v.AutoGroups) {
+			slog.Debug("Setup key matches", "name", v.Name)
+			continue
+		}
+
+		slog.Warn("Updating setup key", "name", v.Name, "old_revoked", nbk.Revoked, "new_revoked", v.Revoked, "old_groups", nbGroupNames, "new_groups", v.AutoGroups)
+		notify.Send(ctx, "", fmt.Sprintf("Updating setup key %s with config: %+v", v.Name, v))
+		gitKey.ID = nbk.ID
+		err = c.netbirdClient.UpdateSetupKey(ctx, gitKey)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, v := range keys {
+		if _, ok := gitKeysRevMap[v.Name]; !ok {
+			slog.Warn("Deleting setup key", "name", v.Name)
+			notify.Send(ctx, "", fmt.Sprintf("Deleting setup key %s as it doesn't exist in Git", v.Name))
+			err = c.netbirdClient.DeleteSetupKey(ctx, v)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// createSetupKey creates setup key and writes its key material to the secret
+// sink, creation is skipped if no secret sink is configured
+func (c Controller) createSetupKey(ctx context.Context, key data.SetupKey) error {
+	if c.SecretSink == nil {
+		slog.Error("No secret sink configured, skipping setup key creation", "name", key.Name)
+		notify.Send(ctx, "", fmt.Sprintf("Cannot create setup key %s: no secret sink configured", key.Name))
+		return nil
+	}
+
+	slog.Warn("Creating setup key", "name", key.Name)
+	notify.Send(ctx, "", fmt.Sprintf("Creating setup key %s", key.Name))
+	created, err := c.netbirdClient.CreateSetupKey(ctx, key)
+	if err != nil {
+		return err
+	}
+
+	if c.netbirdClient.DryRun {
+		return nil
+	}
+	c.keyCreated[created.ID] = time.Now()
+	err = c.SecretSink.Write(ctx, "setup-key-"+key.Name, created.Key)
+	if err != nil {
+		// The key material is lost, delete the key so it is recreated
+		slog.Error("Failed to write setup key, deleting it", "name", key.Name, "err", err)
+		if delErr := c.netbirdClient.DeleteSetupKey(ctx, created); delErr != nil {
+			// Keeps the previous key from being revoked until deleted
+			c.keyUnpublished[created.ID] = true
+			return errors.Join(err, delErr)
+		}
+		return err
+	}
+	return nil
+}
+
+// setupKeyAge returns time since key creation. Creation of keys created by
+// the controller is recorded, others are estimated once from their expiry so
+// later changes of expires_in don't affect rotation
+func (c Controller) setupKeyAge(key data.SetupKey, expiresIn time.Duration) time.Duration {
+	created, ok := c.keyCreated[key.ID]
+	if !ok {
+		created = key.EstimatedCreation(expiresIn)
+		c.keyCreated[key.ID] = created
+	}
+	return time.Since(created)
+}
+
+// latestSetupKeys returns the latest created key per name
+func latestSetupKeys(keys []data.SetupKey) map[string]data.SetupKey {
+	ret := make(map[string]data.SetupKey)
+	for _, k := range keys {
+		if cur, ok := ret[k.Name]; !ok || k.Expires.After(cur.Expires) {
+			ret[k.Name] = k
+		}
+	}
+	return ret
+}
+
+// rotateSetupKeys replaces setup keys older than their rotation interval with
+// new ones, revoking old keys once the overlap period has passed and deleting
+// them once expired. Runs on every poll regardless of git changes, unless
+// autoSync is manual
+func (c Controller) rotateSetupKeys(ctx context.Context, cfg *data.CombinedConfig) error {
+	// A dry run would report the same rotation on every poll
+	if !autoApply(cfg) && !c.SyncOnceAndExit {
+		return nil
+	}
+	rotated := util.Select(cfg.SetupKeys, func(k data.SetupKey) bool { return k.Rotation.Interval > 0 && !k.Revoked })
+	if len(rotated) == 0 {
+		return nil
+	}
+	c.netbirdClient.DryRun = false
+
+	keys, err := c.netbirdClient.ListSetupKeys(ctx)
+	if err != nil {
+		return err
+	}
+	// Forget keys deleted from NetBird
+	keyIDs := util.SliceToMap(keys, func(k data.SetupKey) string { return k.ID })
+	for id := range c.keyCreated {
+		if _, ok := keyIDs[id]; !ok {
+			delete(c.keyCreated, id)
+			delete(c.keyUnpublished, id)
+		}
+	}
+	groups, err := c.netbirdClient.ListGroups(ctx)
+	if err != nil {
+		return err
+	}
+	groupNameID := make(map[string]string)
+	for _, g := range groups {
+		groupNameID[g.Name] = g.ID
+	}
+
+	for _, v := range rotated {
+		named := util.Select(keys, func(k data.SetupKey) bool { return k.Name == v.Name })
+		if len(named) == 0 {
+			// Created by syncSetupKeys
+			continue
+		}
+		// Newest first
+		slices.SortFunc(named, func(a, b data.SetupKey) int { return b.Expires.Compare(a.Expires) })
+
+		newest := named[0]
+		if c.keyUnpublished[newest.ID] {
+			// Publishing failed and so did deleting it, retry the deletion
+			// and keep the previous key valid
+			slog.Warn("Deleting unpublished setup key", "name", newest.Name, "id", newest.ID)
+			err = c.netbirdClient.DeleteSetupKey(ctx, newest)
+			if err != nil {
+				return err
+			}
+			delete(c.keyUnpublished, newest.ID)
+			continue
+		}
+
+		age := c.setupKeyAge(newest, v.ExpiresIn)
+		if age >= v.Rotation.Interval {
+			slog.Warn("Rotating setup key", "name", v.Name, "age", age)
+			notify.Send(ctx, "", fmt.Sprintf("Rotating setup key %s created %s ago, previous key is revoked after %s", v.Name, age.Round(time.Minute), v.Rotation.Overlap))
+			newKey := v
+			newKey.AutoGroups = util.Map(v.AutoGroups, func(g string) string { return groupNameID[g] })
+			err = c.createSetupKey(ctx, newKey)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		for _, old := range named[1:] {
+			switch {
+			case time.Now().After(old.Expires):
+				slog.Warn("Deleting expired rotated setup key", "name", old.Name, "id", old.ID)
+				notify.Send(ctx, "", fmt.Sprintf("Deleting expired rotated setup key %s (%s)", old.Name, old.ID))
+				err = c.netbirdClient.DeleteSetupKey(ctx, old)
+			case !old.Revoked && age >= v.Rotation.Overlap:
+				slog.Warn("Revoking rotated setup key", "name", old.Name, "id", old.ID)
+				notify.Send(ctx, "", fmt.Sprintf("Revoking rotated setup key %s (%s) after overlap of %s", old.Name, old.ID, v.Rotation.Overlap))
+				old.Revoked = true
+				err = c.netbirdClient.UpdateSetupKey(ctx, old)
+			}
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
@@ -2,8 +2,12 @@ package controller
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/mrsool/netbird-gitops/pkg/data"
 	"github.com/mrsool/netbird-gitops/pkg/util"
@@ -13,6 +17,17 @@ import (
 func (c *Controller) doSync(ctx context.Context, cfg *data.CombinedConfig, dryRun bool) error {
 	c.netbirdClient.DryRun = dryRun
 
+	err := c.checkSelfLockout(cfg)
+	if err != nil {
+		slog.Error("Self-lockout prevented", "err", err)
+		return err
+	}
+
+	err = c.syncAccountSettings(ctx, cfg)
+	if err != nil {
+		return fmt.Errorf("Failed syncAccountSettings: %w", err)
+	}
+
 	groupNameID, groupIDName, err := c.syncGroups(ctx, *cfg)
 	if err != nil {
 		return fmt.Errorf("Failed syncGroups: %w", err)
@@ -23,6 +38,16 @@ func (c *Controller) doSync(ctx context.Context, cfg *data.CombinedConfig, dryRu
 		return fmt.Errorf("Failed syncUsers: %w", err)
 	}
 
+	err = c.syncServiceUsers(ctx, cfg, groupNameID, groupIDName)
+	if err != nil {
+		return fmt.Errorf("Failed syncServiceUsers: %w", err)
+	}
+
+	err = c.syncSetupKeys(ctx, cfg, groupNameID, groupIDName)
+	if err != nil {
+		return fmt.Errorf("Failed syncSetupKeys: %w", err)
+	}
+
 	peers, err := c.syncPeers(ctx, cfg)
 	if err != nil {
 		return fmt.Errorf("Failed syncPeers: %w", err)
@@ -43,17 +68,27 @@ func (c *Controller) doSync(ctx context.Context, cfg *data.CombinedConfig, dryRu
 		return fmt.Errorf("Failed syncPostureChecks: %w", err)
 	}
 
-	err = c.syncPolicies(ctx, cfg, pcNameID, groupNameID)
+	resources, err := c.syncNetworks(ctx, cfg, groupNameID)
+	if err != nil {
+		return fmt.Errorf("Failed syncNetworks: %w", err)
+	}
+
+	err = c.syncPolicies(ctx, cfg, pcNameID, groupNameID, resources)
 	if err != nil {
 		return fmt.Errorf("Failed syncPolicies: %w", err)
 	}
 
+	err = c.pruneNetworks(ctx, cfg)
+	if err != nil {
+		return fmt.Errorf("Failed pruneNetworks: %w", err)
+	}
+
 	_, err = c.prunePostureChecks(ctx, cfg)
 	if err != nil {
 		return fmt.Errorf("Failed prunePostureChecks: %w", err)
 	}
 
-	err = c.syncDNSSettings(ctx, cfg, groupNameID)
+	err = c.syncDNSSettings(ctx, cfg, groupNameID, groupIDName)
 	if err != nil {
 		return fmt.Errorf("Failed syncDNSSettings: %w", err)
 	}
@@ -63,6 +98,11 @@ func (c *Controller) doSync(ctx context.Context, cfg *data.CombinedConfig, dryRu
 		return fmt.Errorf("Failed syncNameservers: %w", err)
 	}
 
+	err = c.syncDNSZones(ctx, cfg, groupNameID)
+	if err != nil {
+		return fmt.Errorf("Failed syncDNSZones: %w", err)
+	}
+
 	err = c.pruneGroups(ctx, groupNameID)
 	if err != nil {
 		return fmt.Errorf("Failed pruneGroups: %w", err)
@@ -127,24 +167,33 @@ func (c Controller) syncNameservers(ctx context.Context, cfg *data.CombinedConfi
 	return nil
 }
 
-func (c Controller) syncDNSSettings(ctx context.Context, cfg *data.CombinedConfig, groupNameID map[string]string) error {
+func (c Controller) syncDNSSettings(ctx context.Context, cfg *data.CombinedConfig, groupNameID, groupIDName map[string]string) error {
+	// If DNS settings are not defined in Git config, leave NetBird's untouched
+	if cfg.DNS == nil {
+		slog.Info("No DNS settings defined in Git configuration, skipping DNS settings sync")
+		return nil
+	}
+
 	settings, err := c.netbirdClient.GetDNSSettings(ctx)
 	if err != nil {
 		return err
 	}
 
-	if util.SortedEqual(settings.Items.DisableFor, util.Map(cfg.DNS.DisableFor, func(s string) string { return groupNameID[s] })) {
+	disableFor := cfg.DNS.GetDisableFor()
+	if util.SortedEqual(settings.DisableFor, util.Map(disableFor, func(s string) string { return groupNameID[s] })) {
 		slog.Debug("DNS Settings Matches")
 		return nil
 	}
 
-	slog.Warn("Updating DNS Settings", "disabled_groups", cfg.DNS.DisableFor)
-	notify.Send(ctx, "", fmt.Sprintf("Updating DNS Settings with config: %+v", cfg.DNS))
-	c.netbirdClient.UpdateDNSSettings(ctx, cfg.DNS)
-	return nil
+	current := util.Map(settings.DisableFor, func(s string) string { return groupIDName[s] })
+	slog.Warn("Updating DNS Settings", "old_disabled_groups", current, "new_disabled_groups", disableFor)
+	notify.Send(ctx, "", fmt.Sprintf("Updating DNS Settings: disable_for: %v -> %v", current, disableFor))
+	return c.netbirdClient.UpdateDNSSettings(ctx, data.DNS{
+		DisableFor: util.Map(disableFor, func(s string) string { return groupNameID[s] }),
+	})
 }
 
-func (c Controller) syncPolicies(ctx context.Context, cfg *data.CombinedConfig, pcNameID, groupNameID map[string]string) error {
+func (c Controller) syncPolicies(ctx context.Context, cfg *data.CombinedConfig, pcNameID, groupNameID map[string]string, resources map[string]data.NetworkResource) error {
 	policies, err := c.netbirdClient.ListPolicies(ctx)
 	if err != nil {
 		return err
@@ -165,12 +214,20 @@ func (c Controller) syncPolicies(ctx context.Context, cfg *data.CombinedConfig,
 			Sources:             util.Map(v.Sources, func(p string) string { return groupNameID[p] }),
 			Destinations:        util.Map(v.Destinations, func(p string) string { return groupNameID[p] }),
 		}
+		if v.DestinationResource != "" {
+			gitPolicy.DestinationResource = resources[v.DestinationResource].ID
+			gitPolicy.DestinationResourceType = resources[v.DestinationResource].Type
+		}
+		logArgs := []any{"name", gitPolicy.Name}
+		if v.Schedule != nil {
+			logArgs = append(logArgs, "enabled", gitPolicy.Enabled, "next_transition", nextTransition(v.Schedule, time.Now()))
+		}
 		if nbp, ok := policyRevMap[k]; ok {
 			if nbp.Equals(gitPolicy) {
-				slog.Debug("Policies matching", "name", gitPolicy.Name)
+				slog.Debug("Policies matching", logArgs...)
 				continue
 			}
-			slog.Warn("Updating Policy", "name", gitPolicy.Name)
+			slog.Warn("Updating Policy", logArgs...)
 			notify.Send(ctx, "", fmt.Sprintf("Updating Policy %s with config: %+v", gitPolicy.Name, gitPolicy))
 			gitPolicy.ID = nbp.ID
 			err = c.netbirdClient.UpdatePolicy(ctx, gitPolicy)
@@ -178,7 +235,7 @@ func (c Controller) syncPolicies(ctx context.Context, cfg *data.CombinedConfig,
 				return err
 			}
 		} else {
-			slog.Warn("Creating Policy", "name", gitPolicy.Name)
+			slog.Warn("Creating Policy", logArgs...)
 			notify.Send(ctx, "", fmt.Sprintf("Creating Policy %s with config: %+v", gitPolicy.Name, gitPolicy))
 			err = c.netbirdClient.CreatePolicy(ctx, gitPolicy)
 			if err != nil {
@@ -211,15 +268,21 @@ func (c Controller) syncPostureChecks(ctx context.Context, cfg *data.CombinedCon
 	gitPCRevMap := util.SliceToMap(cfg.PostureChecks, func(p data.PostureCheck) string { return p.Name })
 
 	for k, v := range gitPCRevMap {
+		v = v.Normalize()
 		if nbpc, ok := pcRevMap[k]; ok {
+			pcNameID[v.Name] = nbpc.ID
+			diff := nbpc.Diff(v)
+			if len(diff) == 0 {
+				slog.Debug("PostureChecks matching", "name", v.Name)
+				continue
+			}
 			v.ID = nbpc.ID
-			slog.Warn("Updating postureCheck", "name", v.Name)
-			notify.Send(ctx, "", fmt.Sprintf("Updating postureCheck %s with config: %+v", v.Name, v))
+			slog.Warn("Updating postureCheck", "name", v.Name, "changes", diff)
+			notify.Send(ctx, "", fmt.Sprintf("Updating postureCheck %s: %s", v.Name, strings.Join(diff, ", ")))
 			err = c.netbirdClient.UpdatePostureCheck(ctx, v)
 			if err != nil {
 				return nil, err
 			}
-			pcNameID[v.Name] = nbpc.ID
 		} else {
 			slog.Warn("Creating postureCheck", "name", v.Name)
 			notify.Send(ctx, "", fmt.Sprintf("Creating postureCheck %s with config: %+v", v.Name, v))
@@ -266,6 +329,11 @@ func (c Controller) syncNetworkRoutes(ctx context.Context, cfg *data.CombinedCon
 	routesRevMap := util.SliceToMap(routes, func(r data.NetworkRoute) string { return r.NetworkID })
 	gitRoutesRevMap := util.SliceToMap(cfg.NetworkRoutes, func(r data.NetworkRoute) string { return r.NetworkID })
 
+	err = c.checkRoutingPeers(ctx, cfg)
+	if err != nil {
+		return err
+	}
+
 	for k, v := range routesRevMap {
 		if _, ok := gitRoutesRevMap[k]; !ok {
 			slog.Warn("Deleting network route", "network_id", v.NetworkID)
@@ -279,18 +347,20 @@ func (c Controller) syncNetworkRoutes(ctx context.Context, cfg *data.CombinedCon
 
 	for k, v := range gitRoutesRevMap {
 		gitRoute := data.NetworkRoute{
-			NetworkType: v.NetworkType,
-			Description: v.Description,
-			NetworkID:   v.NetworkID,
-			Enabled:     v.Enabled,
-			Peer:        v.Peer,
-			PeerGroups:  util.Map(v.PeerGroups, func(g string) string { return groupNameID[g] }),
-			Network:     v.Network,
-			Domains:     v.Domains,
-			Metric:      v.Metric,
-			Masquerade:  v.Masquerade,
-			Groups:      util.Map(v.Groups, func(g string) string { return groupNameID[g] }),
-			KeepRoute:   v.KeepRoute,
+			NetworkType:         v.NetworkType,
+			Description:         v.Description,
+			NetworkID:           v.NetworkID,
+			Enabled:             v.Enabled,
+			Peer:                v.Peer,
+			PeerGroups:          util.Map(v.PeerGroups, func(g string) string { return groupNameID[g] }),
+			Network:             v.Network,
+			Domains:             v.Domains,
+			Metric:              v.Metric,
+			Masquerade:          v.Masquerade,
+			Groups:              util.Map(v.Groups, func(g string) string { return groupNameID[g] }),
+			KeepRoute:           v.KeepRoute,
+			AccessControlGroups: util.Map(v.AccessControlGroups, func(g string) string { return groupNameID[g] }),
+			SkipAutoApply:       v.SkipAutoApply,
 		}
 
 		if _, ok := routesRevMap[k]; !ok {
@@ -318,6 +388,37 @@ func (c Controller) syncNetworkRoutes(ctx context.Context, cfg *data.CombinedCon
 	return nil
 }
 
+// checkRoutingPeers logs enabled routes whose routing peer groups have no
+// peers in NetBird, such routes are not served by anyone. Runs on every sync,
+// so it only logs instead of notifying
+func (c Controller) checkRoutingPeers(ctx context.Context, cfg *data.CombinedConfig) error {
+	if len(cfg.NetworkRoutes) == 0 {
+		return nil
+	}
+
+	peers, err := c.netbirdClient.ListPeers(ctx)
+	if err != nil {
+		return err
+	}
+
+	populated := make(map[string]bool)
+	for _, p := range peers {
+		for _, g := range p.Groups {
+			populated[g.Name] = true
+		}
+	}
+
+	for _, r := range cfg.NetworkRoutes {
+		if !r.Enabled || len(r.PeerGroups) == 0 {
+			continue
+		}
+		if len(util.Select(r.PeerGroups, func(g string) bool { return populated[g] })) == 0 {
+			slog.Warn("Network route has no routing peers", "network_id", r.NetworkID, "peer_groups", r.PeerGroups)
+		}
+	}
+	return nil
+}
+
 func (c Controller) syncPeerGroups(ctx context.Context, cfg *data.CombinedConfig, users map[string]data.User, peers map[string]data.Peer, groupNameID map[string]string) error {
 	groups, err := c.netbirdClient.ListGroups(ctx)
 	if err != nil {
@@ -355,6 +456,9 @@ func (c Controller) syncPeerGroups(ctx context.Context, cfg *data.CombinedConfig
 		}
 	}
 
+	expandGroupDefinitions(cfg, reverseGroupMappingGit, users, peers, groupNameID)
+	quarantinePeers(cfg, reverseGroupMappingGit, peers, groupNameID)
+
 	for _, g := range groups {
 		if g.Name == "All" {
 			continue
@@ -386,12 +490,170 @@ func (c Controller) syncPeerGroups(ctx context.Context, cfg *data.CombinedConfig
 	return nil
 }
 
+// expandGroupDefinitions adds members of composite groups and peers matching
+// selectors defined in cfg.Groups to mapping of group ID to peer IDs
+func expandGroupDefinitions(cfg *data.CombinedConfig, mapping map[string][]string, users map[string]data.User, peers map[string]data.Peer, groupNameID map[string]string) {
+	defs := util.SliceToMap(cfg.Groups, func(g data.GroupDefinition) string { return g.Name })
+	userEmailID := make(map[string]string)
+	for _, u := range users {
+		if u.Email != "" {
+			userEmailID[strings.ToLower(u.Email)] = u.ID
+		}
+	}
+
+	expanded := make(map[string][]string)
+	var expand func(name string) []string
+	expand = func(name string) []string {
+		if members, ok := expanded[name]; ok {
+			return members
+		}
+		// Guard against cycles, config validation rejects those beforehand
+		expanded[name] = nil
+
+		members := slices.Clone(mapping[groupNameID[name]])
+		if def, ok := defs[name]; ok {
+			for _, g := range def.Groups {
+				members = append(members, expand(g)...)
+			}
+			members = append(members, def.Peers...)
+			for _, email := range def.Users {
+				userID, ok := userEmailID[strings.ToLower(email)]
+				if !ok {
+					slog.Warn("User in group definition not found in NetBird", "group", name, "email", email)
+					continue
+				}
+				for _, p := range peers {
+					if p.UserID == userID {
+						members = append(members, p.ID)
+					}
+				}
+			}
+			for _, p := range peers {
+				for _, sel := range def.Selectors {
+					if sel.Matches(p, users[p.UserID].Email) {
+						members = append(members, p.ID)
+						break
+					}
+				}
+			}
+		}
+
+		members = util.Unique(members)
+		expanded[name] = members
+		return members
+	}
+
+	for name := range defs {
+		slog.Debug("Expanded group definition", "name", name, "peers", expand(name))
+	}
+	for name := range defs {
+		mapping[groupNameID[name]] = expanded[name]
+	}
+}
+
+// resolvePeerRefs replaces peer names, hostnames and DNS labels in cfg with
+// peer IDs. Unknown references are kept as-is
+func resolvePeerRefs(cfg *data.CombinedConfig, peers []data.Peer) error {
+	resolve := func(ref string) (string, error) {
+		p, err := data.ResolvePeer(peers, ref)
+		if errors.Is(err, data.ErrPeerNotFound) {
+			return ref, nil
+		}
+		if err != nil {
+			return "", err
+		}
+		if p.ID != ref {
+			slog.Debug("Resolved peer reference", "ref", ref, "id", p.ID)
+		}
+		return p.ID, nil
+	}
+
+	var err error
+	seen := make(map[string]string)
+	for idx, p := range cfg.Peers {
+		ref := p.ID
+		cfg.Peers[idx].ID, err = resolve(ref)
+		if err != nil {
+			return fmt.Errorf("peers: %w", err)
+		}
+		if other, ok := seen[cfg.Peers[idx].ID]; ok {
+			return fmt.Errorf("peers: %s and %s refer to the same peer %s", other, ref, cfg.Peers[idx].ID)
+		}
+		seen[cfg.Peers[idx].ID] = ref
+	}
+	for idx, r := range cfg.NetworkRoutes {
+		if r.Peer == "" {
+			continue
+		}
+		cfg.NetworkRoutes[idx].Peer, err = resolve(r.Peer)
+		if err != nil {
+			return fmt.Errorf("network_routes: %s: %w", r.NetworkID, err)
+		}
+	}
+	for idx, n := range cfg.Networks {
+		for rIdx, r := range n.Routers {
+			if r.Peer == "" {
+				continue
+			}
+			cfg.Networks[idx].Routers[rIdx].Peer, err = resolve(r.Peer)
+			if err != nil {
+				return fmt.Errorf("networks: %s: %w", n.Name, err)
+			}
+		}
+	}
+	for idx, g := range cfg.Groups {
+		for pIdx, ref := range g.Peers {
+			cfg.Groups[idx].Peers[pIdx], err = resolve(ref)
+			if err != nil {
+				return fmt.Errorf("groups: %s: %w", g.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// quarantinePeers moves peers missing from git with the quarantine action out
+// of all groups and into their quarantine group in mapping of group ID to peer IDs
+func quarantinePeers(cfg *data.CombinedConfig, mapping map[string][]string, peers map[string]data.Peer, groupNameID map[string]string) {
+	// Peer sync is skipped entirely if no peers are defined
+	if len(cfg.Peers) == 0 {
+		return
+	}
+
+	gitPeerRevMap := util.SliceToMap(cfg.Peers, func(p data.Peer) string { return p.ID })
+	quarantined := make(map[string]string)
+	for k, p := range peers {
+		if _, ok := gitPeerRevMap[k]; ok {
+			continue
+		}
+		policy := cfg.Config.UnmanagedPeers.For(p)
+		if policy.GetAction() == data.UnmanagedPeerQuarantine {
+			quarantined[k] = groupNameID[policy.QuarantineGroup]
+		}
+	}
+	if len(quarantined) == 0 {
+		return
+	}
+
+	for g, members := range mapping {
+		mapping[g] = util.Select(members, func(s string) bool { _, ok := quarantined[s]; return !ok })
+	}
+	for p, g := range quarantined {
+		mapping[g] = append(mapping[g], p)
+	}
+}
+
 func (c Controller) syncPeers(ctx context.Context, cfg *data.CombinedConfig) (map[string]data.Peer, error) {
 	peers, err := c.netbirdClient.ListPeers(ctx)
 	if err != nil {
 		return nil, err
 	}
 
+	err = resolvePeerRefs(cfg, peers)
+	if err != nil {
+		return nil, err
+	}
+
 	// If no peers are defined in Git config, skip peer sync entirely
 	if len(cfg.Peers) == 0 {
 		slog.Info("No peers defined in Git configuration, skipping peer sync")
@@ -404,10 +666,57 @@ func (c Controller) syncPeers(ctx context.Context, cfg *data.CombinedConfig) (ma
 
 	gitPeerRevMap := util.SliceToMap(cfg.Peers, func(v data.Peer) string { return v.ID })
 
+	// A git peer that doesn't resolve may have been renamed, its NetBird peer
+	// then looks unmanaged and must not be deleted
+	nbPeerRevMap := util.SliceToMap(peers, func(p data.Peer) string { return p.ID })
+	var unresolved []string
+	for _, p := range cfg.Peers {
+		if _, ok := nbPeerRevMap[p.ID]; !ok {
+			unresolved = append(unresolved, p.ID)
+		}
+	}
+	if len(unresolved) > 0 {
+		slog.Warn("Peers in Git not found in NetBird, not deleting unmanaged peers", "peers", unresolved)
+	}
+
+	deleted := make(map[string]bool)
 	for _, p := range peers {
 		gitPeer := gitPeerRevMap[p.ID]
+		approvalRequired := gitPeer.GetApprovalRequired(p.ApprovalRequired, cfg.Config.PeerApproval)
+		inactivityExpiration := gitPeer.GetInactivityExpirationEnabled(p.InactivityExpirationEnabled)
 		if _, ok := gitPeerRevMap[p.ID]; !ok {
-			// TODO: Delete?
+			policy := cfg.Config.UnmanagedPeers.For(p)
+			action := policy.GetAction()
+			if action == data.UnmanagedPeerDelete {
+				if !p.Connected && len(unresolved) == 0 && time.Since(p.LastSeen) >= policy.GracePeriod {
+					slog.Warn("Peer exists in NetBird but not in Git, deleting", "id", p.ID, "name", p.Name, "last_seen", p.LastSeen)
+					notify.Send(ctx, "", fmt.Sprintf("Peer %s (%s) doesn't exist in source control and was last seen %s: deleting", p.ID, p.Name, p.LastSeen.Format(time.RFC3339)))
+					err = c.netbirdClient.DeletePeer(ctx, p)
+					if err != nil {
+						return nil, err
+					}
+					deleted[p.ID] = true
+					continue
+				}
+				slog.Debug("Peer exists in NetBird but not in Git, restricting until grace period elapses", "id", p.ID, "last_seen", p.LastSeen, "connected", p.Connected)
+				action = data.UnmanagedPeerRestrict
+			}
+
+			switch action {
+			case data.UnmanagedPeerIgnore:
+				slog.Debug("Peer exists in NetBird but not in Git, ignoring", "id", p.ID)
+				continue
+			case data.UnmanagedPeerQuarantine:
+				// Group membership is handled by syncPeerGroups
+				otherGroups := util.Select(p.Groups, func(g data.Group) bool { return g.Name != "All" && g.Name != policy.QuarantineGroup })
+				if len(otherGroups) == 0 && slices.ContainsFunc(p.Groups, func(g data.Group) bool { return g.Name == policy.QuarantineGroup }) {
+					continue
+				}
+				slog.Warn("Peer exists in NetBird but not in Git, moving to quarantine group", "id", p.ID, "group", policy.QuarantineGroup)
+				notify.Send(ctx, "", fmt.Sprintf("Peer %s (%s) doesn't exist in source control: moving to quarantine group %s", p.ID, p.Name, policy.QuarantineGroup))
+				continue
+			}
+
 			if p.LoginExpirationEnabled && !p.SSHEnabled {
 				continue
 			}
@@ -415,16 +724,24 @@ func (c Controller) syncPeers(ctx context.Context, cfg *data.CombinedConfig) (ma
 			notify.Send(ctx, "", fmt.Sprintf("Peer %s doesn't exist in source control: disabling SSH and enabling login expiration", p.ID))
 			p.LoginExpirationEnabled = true
 			p.SSHEnabled = false
-		} else if p.LoginExpirationEnabled == !gitPeer.ExpirationDisabled && p.Name == gitPeer.Name && p.SSHEnabled == gitPeer.SSHEnabled {
+		} else if p.LoginExpirationEnabled == !gitPeer.ExpirationDisabled &&
+			boolPtrEqual(p.InactivityExpirationEnabled, inactivityExpiration) &&
+			boolPtrEqual(p.ApprovalRequired, approvalRequired) &&
+			p.Name == gitPeer.Name && p.SSHEnabled == gitPeer.SSHEnabled {
 			slog.Debug("Peer matches git", "id", p.ID, "name", p.Name)
 			continue
 		} else {
 			slog.Warn("Updating Peer", "id", p.ID,
 				"old_name", p.Name, "new_name", gitPeer.Name,
 				"old_expiration_enabled", p.LoginExpirationEnabled, "new_expiration_enabled", !gitPeer.ExpirationDisabled,
+				"old_inactivity_expiration_enabled", boolPtrString(p.InactivityExpirationEnabled), "new_inactivity_expiration_enabled", boolPtrString(inactivityExpiration),
+				"old_approval_required", boolPtrString(p.ApprovalRequired), "new_approval_required", boolPtrString(approvalRequired),
 				"old_ssh_enabled", p.SSHEnabled, "new_ssh_enabled", gitPeer.SSHEnabled)
-			notify.Send(ctx, "", fmt.Sprintf("Updating peer %s with config: %+v", p.ID, p))
+			notify.Send(ctx, "", fmt.Sprintf("Updating peer %s with config: name: %s, ssh_enabled: %t, login_expiration_enabled: %t, inactivity_expiration_enabled: %s, approval_required: %s",
+				p.ID, gitPeer.Name, gitPeer.SSHEnabled, !gitPeer.ExpirationDisabled, boolPtrString(inactivityExpiration), boolPtrString(approvalRequired)))
 			p.LoginExpirationEnabled = !gitPeer.ExpirationDisabled
+			p.InactivityExpirationEnabled = inactivityExpiration
+			p.ApprovalRequired = approvalRequired
 			p.SSHEnabled = gitPeer.SSHEnabled
 			p.Name = gitPeer.Name
 			p.GroupNames = gitPeer.GroupNames
@@ -436,7 +753,7 @@ func (c Controller) syncPeers(ctx context.Context, cfg *data.CombinedConfig) (ma
 		}
 	}
 
-	peerRevMap := util.SliceToMap(peers, func(p data.Peer) string { return p.ID })
+	peerRevMap := util.SliceToMap(util.Select(peers, func(p data.Peer) bool { return !deleted[p.ID] }), func(p data.Peer) string { return p.ID })
 	for k := range gitPeerRevMap {
 		if _, ok := peerRevMap[k]; !ok {
 			slog.Warn("Peer exists in Git but not NetBird, Deleted from upstream?", "id", k)
@@ -447,6 +764,22 @@ func (c Controller) syncPeers(ctx context.Context, cfg *data.CombinedConfig) (ma
 	return peerRevMap, nil
 }
 
+// boolPtrEqual returns if both are unset or set to the same value
+func boolPtrEqual(a, b *bool) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// boolPtrString formats an optional bool for logs and notifications
+func boolPtrString(b *bool) string {
+	if b == nil {
+		return "unset"
+	}
+	return fmt.Sprint(*b)
+}
+
 func (c Controller) syncUsers(ctx context.Context, cfg *data.CombinedConfig, groupNameID, groupIDName map[string]string) (map[string]data.User, error) {
 	users, err := c.netbirdClient.ListUsers(ctx)
 	if err != nil {
@@ -464,36 +797,42 @@ func (c Controller) syncUsers(ctx context.Context, cfg *data.CombinedConfig, gro
 		return userRevMap, nil
 	}
 
-	emailMappingGit := util.SliceToMap(cfg.Users, func(v data.User) string { return v.Email })
+	guard := newUserGuard(users, c.self)
+
+	// Emails are case-insensitive, NetBird may store them in a different case
+	emailMappingGit := util.SliceToMap(cfg.Users, func(v data.User) string { return strings.ToLower(v.Email) })
 	for _, u := range users {
 		userRevMap[u.ID] = u
 		if u.ServiceUser {
-			// TODO: Manage service users
+			// Managed by syncServiceUsers
 			continue
 		}
 		if u.Email == "" {
-			slog.Warn("User exists in NetBird with no email", "id", u.ID)
-			notify.Send(ctx, "", fmt.Sprintf("User ID %s exists in NetBird with no email, most likely deleted from SSO", u.ID))
-			// TODO: Handle deleted user
+			err = c.handleSSODeletedUser(ctx, cfg, guard, u)
+			if err != nil {
+				return nil, err
+			}
+			continue
 		}
-		gitUser := emailMappingGit[u.Email]
+		gitUser := emailMappingGit[strings.ToLower(u.Email)]
 		nbUserGroupNames := util.Map(u.Groups, func(a string) string { return groupIDName[a] })
 		if gitUser.Email == "" {
 			// User exists in NetBird but not git
-			// TODO: Deletion or just blocking?
-			slog.Warn("User exists in NetBird but not in Git", "email", u.Email)
-			notify.Send(ctx, "", fmt.Sprintf("User %s exists in NetBird but not in Git, user blocked", u.Email))
-
-			// Skip blocking users with empty emails as they cause API errors
-			if u.Email == "" {
-				slog.Warn("Skipping block for user with empty email", "id", u.ID)
-				continue
+			err = c.handleRemovedUser(ctx, cfg, guard, u)
+			if err != nil {
+				return nil, err
 			}
+			continue
+		}
+		delete(c.blockedAt, u.ID)
 
-			u.Blocked = true
-			u.Groups = []string{}
-			u.Role = ""
-		} else if util.SortedEqual(gitUser.Groups, nbUserGroupNames) && u.Role == gitUser.GetRole() {
+		role := gitUser.GetRole()
+		if role != u.Role && guard.allow(ctx, u, role, false) != nil {
+			// Keep current role, groups are still synced
+			role = u.Role
+		}
+
+		if util.SortedEqual(gitUser.Groups, nbUserGroupNames) && u.Role == role {
 			slog.Debug("User matches in Netbird and Git", "email", u.Email)
 			// User autogroups and role equal
 			continue
@@ -501,9 +840,9 @@ func (c Controller) syncUsers(ctx context.Context, cfg *data.CombinedConfig, gro
 
 		// User autogroups not equal
 		// Map group names to IDs
-		slog.Warn("Updating user", "email", u.Email, "old_groups", nbUserGroupNames, "new_groups", gitUser.Groups, "old_role", u.Role, "new_role", gitUser.GetRole())
+		slog.Warn("Updating user", "email", u.Email, "old_groups", nbUserGroupNames, "new_groups", gitUser.Groups, "old_role", u.Role, "new_role", role)
 		u.Groups = util.Map(gitUser.Groups, func(a string) string { return groupNameID[a] })
-		u.Role = gitUser.GetRole()
+		u.Role = role
 		notify.Send(ctx, "", fmt.Sprintf("Updating user %s with config: %+v", u.Email, u))
 
 		err := c.netbirdClient.UpdateUser(ctx, u)
@@ -512,40 +851,33 @@ func (c Controller) syncUsers(ctx context.Context, cfg *data.CombinedConfig, gro
 		}
 	}
 
+	c.trackPendingInvites(ctx, cfg, users)
+
+	nbEmails := util.SliceToMap(users, func(u data.User) string { return strings.ToLower(u.Email) })
+	for k, v := range emailMappingGit {
+		if _, ok := nbEmails[k]; ok {
+			continue
+		}
+		// Invites may fail for reasons outside the config, such as an IdP not
+		// allowing user creation, which must not block the rest of the sync
+		err = c.inviteUser(ctx, v, groupNameID)
+		if err != nil {
+			slog.Error("Failed to invite user", "email", v.Email, "err", err)
+			notify.Send(ctx, "Invite failed", fmt.Sprintf("Failed to invite user %s with error: %s", v.Email, err.Error()))
+		}
+	}
+
 	return userRevMap, nil
 }
 
 func (c Controller) syncGroups(ctx context.Context, cfg data.CombinedConfig) (groupNameToID map[string]string, groupIDToName map[string]string, err error) {
 	// Get all groups from all configuration
 	groupNameToID = make(map[string]string)
-	for _, g := range cfg.DNS.DisableFor {
+	for _, g := range cfg.ReferencedGroups() {
 		groupNameToID[g] = ""
 	}
-	for _, route := range cfg.NetworkRoutes {
-		for _, g := range route.Groups {
-			groupNameToID[g] = ""
-		}
-		for _, g := range route.PeerGroups {
-			groupNameToID[g] = ""
-		}
-	}
-	for _, peer := range cfg.Peers {
-		for _, g := range peer.Groups {
-			groupNameToID[g.Name] = ""
-		}
-	}
-	for _, policy := range cfg.Policies {
-		for _, g := range policy.Sources {
-			groupNameToID[g] = ""
-		}
-		for _, g := range policy.Destinations {
-			groupNameToID[g] = ""
-		}
-	}
-	for _, user := range cfg.Users {
-		for _, g := range user.Groups {
-			groupNameToID[g] = ""
-		}
+	for _, g := range cfg.Groups {
+		groupNameToID[g.Name] = ""
 	}
 
 	// Get NetBird groups
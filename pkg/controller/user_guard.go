@@ -0,0 +1,119 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/mrsool/netbird-gitops/pkg/data"
+	"github.com/nikoksr/notify"
+)
+
+// userGuard protects the last owner and the API token's own user from being
+// demoted, blocked or deleted
+type userGuard struct {
+	selfID string
+	owners map[string]bool
+}
+
+func newUserGuard(users []data.User, self data.User) *userGuard {
+	g := &userGuard{
+		selfID: self.ID,
+		owners: make(map[string]bool),
+	}
+	for _, u := range users {
+		if u.Role == data.RoleOwner && !u.Blocked {
+			g.owners[u.ID] = true
+		}
+	}
+	return g
+}
+
+// roleRank orders roles by privilege for demotion checks
+func roleRank(role string) int {
+	switch role {
+	case data.RoleOwner:
+		return 2
+	case data.RoleAdmin:
+		return 1
+	}
+	return 0
+}
+
+// allow returns nil if u may be changed to role, or blocked/deleted if remove
+// is set, and records the change. Otherwise the change is reported and an
+// error describing the refusal is returned
+func (g *userGuard) allow(ctx context.Context, u data.User, role string, remove bool) error {
+	var reason string
+	switch {
+	case u.ID == g.selfID && (remove || roleRank(role) < roleRank(u.Role)):
+		reason = "it is the API token's own user"
+	case g.owners[u.ID] && len(g.owners) == 1 && (remove || role != data.RoleOwner):
+		reason = "it is the last owner"
+	}
+
+	if reason != "" {
+		err := fmt.Errorf("refusing to change user %s (%s) to role %q (remove: %t) as %s", u.ID, u.Email, role, remove, reason)
+		slog.Error("Refusing user change", "id", u.ID, "email", u.Email, "role", role, "remove", remove, "reason", reason)
+		notify.Send(ctx, "", err.Error())
+		return err
+	}
+
+	if remove || role != data.RoleOwner {
+		delete(g.owners, u.ID)
+	}
+	return nil
+}
+
+// checkSelfLockout refuses configuration that would block, demote or delete
+// the API token's own user, which would break the controller itself
+func (c Controller) checkSelfLockout(cfg *data.CombinedConfig) error {
+	self := c.self
+	if self.ID == "" {
+		return nil
+	}
+
+	var gitRole string
+	var found bool
+	if self.ServiceUser {
+		// Service users are only managed if at least one is defined
+		if len(cfg.ServiceUsers) == 0 {
+			return nil
+		}
+		for _, u := range cfg.ServiceUsers {
+			if u.Name == self.Name {
+				gitRole, found = u.GetRole(), true
+			}
+		}
+		if !found {
+			return fmt.Errorf("refusing to sync: API token's own service user %s is missing from service_users and would be deleted", self.Name)
+		}
+	} else {
+		// Users are only managed if at least one is defined
+		if len(cfg.Users) == 0 {
+			return nil
+		}
+		for _, u := range cfg.Users {
+			if strings.EqualFold(u.Email, self.Email) {
+				gitRole, found = u.GetRole(), true
+			}
+		}
+		if !found {
+			if cfg.Config.RemovedUsers.GetAction(data.RemovedUserBlock) == data.RemovedUserIgnore {
+				return nil
+			}
+			return fmt.Errorf("refusing to sync: API token's own user %s is missing from users and removedUsers action is %s", self.Email, cfg.Config.RemovedUsers.GetAction(data.RemovedUserBlock))
+		}
+	}
+
+	// Only refuse actual changes, the token user may already have a role below admin
+	if gitRole != self.Role && (roleRank(gitRole) < roleRank(self.Role) || roleRank(gitRole) < roleRank(data.RoleAdmin)) {
+		principal := self.Email
+		if self.ServiceUser {
+			principal = self.Name
+		}
+		return fmt.Errorf("refusing to sync: API token's own user %s would be changed from role %s to %s", principal, self.Role, gitRole)
+	}
+	return nil
+}
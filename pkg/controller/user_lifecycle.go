@@ -0,0 +1,90 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/mrsool/netbird-gitops/pkg/data"
+	"github.com/nikoksr/notify"
+)
+
+// handleRemovedUser applies config.removedUsers to a user that exists in
+// NetBird but not in Git. Block times are kept in memory, users found blocked
+// after a restart are deleted deleteAfter from then
+func (c Controller) handleRemovedUser(ctx context.Context, cfg *data.CombinedConfig, guard *userGuard, u data.User) error {
+	if !u.Blocked {
+		delete(c.blockedAt, u.ID)
+	}
+
+	policy := cfg.Config.RemovedUsers
+	switch policy.GetAction(data.RemovedUserBlock) {
+	case data.RemovedUserIgnore:
+		slog.Debug("User exists in NetBird but not in Git, ignoring", "email", u.Email)
+		return nil
+	case data.RemovedUserDelete:
+		return c.deleteRemovedUser(ctx, guard, u, "it doesn't exist in Git")
+	case data.RemovedUserBlockThenDelete:
+		if u.Blocked {
+			blockedAt, ok := c.blockedAt[u.ID]
+			if !ok {
+				blockedAt = time.Now()
+				c.blockedAt[u.ID] = blockedAt
+			}
+			if time.Since(blockedAt) >= policy.DeleteAfter {
+				return c.deleteRemovedUser(ctx, guard, u, fmt.Sprintf("it doesn't exist in Git and was blocked at %s", blockedAt.Format(time.RFC3339)))
+			}
+		}
+	}
+
+	if u.Blocked && len(u.Groups) == 0 && u.Role == data.RoleUser {
+		slog.Debug("User exists in NetBird but not in Git, already blocked", "email", u.Email)
+		return nil
+	}
+
+	if guard.allow(ctx, u, data.RoleUser, true) != nil {
+		return nil
+	}
+
+	slog.Warn("User exists in NetBird but not in Git, blocking", "email", u.Email, "old_groups", u.Groups, "old_role", u.Role)
+	notify.Send(ctx, "", fmt.Sprintf("User %s exists in NetBird but not in Git, user blocked and groups and role cleared", u.Email))
+	u.Blocked = true
+	u.Groups = []string{}
+	u.Role = data.RoleUser
+	err := c.netbirdClient.UpdateUser(ctx, u)
+	if err != nil {
+		return err
+	}
+	if !c.netbirdClient.DryRun {
+		c.blockedAt[u.ID] = time.Now()
+	}
+	return nil
+}
+
+// handleSSODeletedUser applies config.ssoDeletedUsers to a user without an
+// email, which is most likely deleted from SSO
+func (c Controller) handleSSODeletedUser(ctx context.Context, cfg *data.CombinedConfig, guard *userGuard, u data.User) error {
+	if cfg.Config.SSODeletedUsers.GetAction(data.RemovedUserIgnore) == data.RemovedUserDelete {
+		return c.deleteRemovedUser(ctx, guard, u, "it has no email and was most likely deleted from SSO")
+	}
+
+	slog.Warn("User exists in NetBird with no email", "id", u.ID)
+	notify.Send(ctx, "", fmt.Sprintf("User ID %s exists in NetBird with no email, most likely deleted from SSO", u.ID))
+	return nil
+}
+
+func (c Controller) deleteRemovedUser(ctx context.Context, guard *userGuard, u data.User, reason string) error {
+	if guard.allow(ctx, u, u.Role, true) != nil {
+		return nil
+	}
+
+	slog.Warn("Deleting user", "id", u.ID, "email", u.Email, "reason", reason)
+	notify.Send(ctx, "", fmt.Sprintf("Deleting user %s (%s) as %s", u.ID, u.Email, reason))
+	err := c.netbirdClient.DeleteUser(ctx, u)
+	if err != nil {
+		return err
+	}
+	delete(c.blockedAt, u.ID)
+	return nil
+}
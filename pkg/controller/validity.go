@@ -0,0 +1,240 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/mrsool/netbird-gitops/pkg/data"
+	"github.com/mrsool/netbird-gitops/pkg/util"
+	"github.com/nikoksr/notify"
+)
+
+// defaultExpiryNotice how long before expiry time-bound entries are announced
+const defaultExpiryNotice = 24 * time.Hour
+
+// checkValidity announces time-bound entries expiring soon and entries that
+// became active or inactive by validity or schedule since the last check,
+// recording those for applyValidity. State is kept in memory, so transitions
+// during downtime are applied but not announced
+func (c *Controller) checkValidity(ctx context.Context, cfg *data.CombinedConfig, now time.Time) {
+	notice := cfg.Config.ExpiryNotice
+	if notice == 0 {
+		notice = defaultExpiryNotice
+	}
+
+	pending := ""
+	if !autoApply(cfg) {
+		pending = ", applied with the next sync as autoSync is manual"
+	}
+
+	seen := make(map[string]bool)
+	for _, e := range cfg.TimeBoundEntries() {
+		seen[e.Key] = true
+		active := e.ActiveAt(now)
+
+		next := nextTransition(e.Schedule, now)
+
+		prev, ok := c.validity[e.Key]
+		if ok && prev != active {
+			c.unapplied[e.Key] = true
+			if active {
+				slog.Warn("Time-bound entry became active", "entry", e.Key)
+				notify.Send(ctx, "", fmt.Sprintf("%s is now active%s%s", e.Description, pending, nextSuffix(next)))
+			} else if e.ValidUntil != nil && !now.Before(*e.ValidUntil) {
+				slog.Warn("Time-bound entry expired", "entry", e.Key)
+				notify.Send(ctx, "", fmt.Sprintf("%s has expired%s", e.Description, pending))
+			} else {
+				slog.Warn("Time-bound entry became inactive", "entry", e.Key)
+				notify.Send(ctx, "", fmt.Sprintf("%s is now inactive%s%s", e.Description, pending, nextSuffix(next)))
+			}
+		}
+		if e.Schedule != nil && (!ok || prev != active) {
+			slog.Info("Scheduled entry", "entry", e.Key, "active", active, "next_transition", next)
+		}
+		c.validity[e.Key] = active
+
+		if active && e.ValidUntil != nil && e.ValidUntil.Sub(now) <= notice && !c.expiryNotified[e.Key] {
+			slog.Warn("Time-bound entry expires soon", "entry", e.Key, "valid_until", *e.ValidUntil)
+			notify.Send(ctx, "", fmt.Sprintf("%s expires at %s", e.Description, e.ValidUntil.Format(time.RFC3339)))
+			c.expiryNotified[e.Key] = true
+		}
+	}
+
+	// Forget entries removed from git
+	for k := range c.validity {
+		if !seen[k] {
+			delete(c.validity, k)
+			delete(c.unapplied, k)
+			delete(c.expiryNotified, k)
+		}
+	}
+}
+
+// applyValidity applies transitions recorded by checkValidity without a full
+// sync, only updating the affected policies, users and group memberships to
+// the state set by cfg.ApplyValidity. Entries are retried until applied
+func (c *Controller) applyValidity(ctx context.Context, cfg *data.CombinedConfig, now time.Time) error {
+	if len(c.unapplied) == 0 {
+		return nil
+	}
+	c.netbirdClient.DryRun = false
+
+	policies, err := c.netbirdClient.ListPolicies(ctx)
+	if err != nil {
+		return err
+	}
+	users, err := c.netbirdClient.ListUsers(ctx)
+	if err != nil {
+		return err
+	}
+	peers, err := c.netbirdClient.ListPeers(ctx)
+	if err != nil {
+		return err
+	}
+	groups, err := c.netbirdClient.ListGroups(ctx)
+	if err != nil {
+		return err
+	}
+
+	policyRevMap := util.SliceToMap(policies, func(p data.Policy) string { return p.Name })
+	userRevMap := util.SliceToMap(users, func(u data.User) string { return strings.ToLower(u.Email) })
+	peerRevMap := util.SliceToMap(peers, func(p data.Peer) string { return p.ID })
+	groupNameID := make(map[string]string)
+	groupRevMap := make(map[string]*data.Group)
+	for idx, g := range groups {
+		groupNameID[g.Name] = g.ID
+		groups[idx].Peers = util.Map(g.PeerData, func(p data.Peer) string { return p.ID })
+		groupRevMap[g.ID] = &groups[idx]
+	}
+	gitPolicyRevMap := util.SliceToMap(cfg.Policies, func(p data.Policy) string { return p.Name })
+	gitUserRevMap := util.SliceToMap(cfg.Users, func(u data.User) string { return strings.ToLower(u.Email) })
+	gitPeerRevMap := util.SliceToMap(cfg.Peers, func(p data.Peer) string { return p.ID })
+
+	var errs []error
+	for _, e := range cfg.TimeBoundEntries() {
+		if !c.unapplied[e.Key] {
+			continue
+		}
+
+		var err error
+		switch e.Kind {
+		case data.TimeBoundPolicy:
+			err = c.applyPolicyValidity(ctx, policyRevMap[e.Name], gitPolicyRevMap[e.Name], now)
+		case data.TimeBoundUser:
+			want := slices.Contains(gitUserRevMap[strings.ToLower(e.Name)].Groups, e.Group)
+			u, ok := userRevMap[strings.ToLower(e.Name)]
+			if !ok {
+				// Created with the next sync
+				break
+			}
+			err = c.applyUserGroup(ctx, cfg, u, peers, groupRevMap[groupNameID[e.Group]], want)
+		case data.TimeBoundPeer:
+			want := slices.Contains(gitPeerRevMap[e.Name].GroupNames, e.Group)
+			p, ok := peerRevMap[e.Name]
+			if !ok || (!cfg.Config.IndividualPeerGroups && p.UserID != "") {
+				// Groups of user peers follow their user
+				break
+			}
+			err = c.applyPeerGroup(ctx, groupRevMap[groupNameID[e.Group]], []string{p.ID}, want)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", e.Key, err))
+			continue
+		}
+		delete(c.unapplied, e.Key)
+	}
+	return errors.Join(errs...)
+}
+
+// applyPolicyValidity enables or disables an existing NetBird policy as git
+// wants it, leaving the rest of the policy untouched
+func (c Controller) applyPolicyValidity(ctx context.Context, nbp, gitPolicy data.Policy, now time.Time) error {
+	if nbp.ID == "" || nbp.Enabled == gitPolicy.Enabled {
+		return nil
+	}
+	slog.Warn("Updating Policy", "name", nbp.Name, "old_enabled", nbp.Enabled, "new_enabled", gitPolicy.Enabled, "next_transition", nextTransition(gitPolicy.Schedule, now))
+	nbp.Enabled = gitPolicy.Enabled
+	return c.netbirdClient.UpdatePolicy(ctx, nbp)
+}
+
+// applyUserGroup adds or removes a group from a user's auto groups and, unless
+// peers have individual groups, the user's peers from the group
+func (c Controller) applyUserGroup(ctx context.Context, cfg *data.CombinedConfig, u data.User, peers []data.Peer, group *data.Group, want bool) error {
+	if group == nil {
+		return errors.New("group not found, it is created with the next sync")
+	}
+
+	if slices.Contains(u.Groups, group.ID) != want {
+		if want {
+			u.Groups = append(u.Groups, group.ID)
+		} else {
+			u.Groups = slices.DeleteFunc(u.Groups, func(g string) bool { return g == group.ID })
+		}
+		slog.Warn("Updating user", "email", u.Email, "group", group.Name, "member", want)
+		err := c.netbirdClient.UpdateUser(ctx, u)
+		if err != nil {
+			return err
+		}
+	}
+
+	if cfg.Config.IndividualPeerGroups {
+		return nil
+	}
+	userPeers := util.Select(peers, func(p data.Peer) bool { return p.UserID == u.ID })
+	return c.applyPeerGroup(ctx, group, util.Map(userPeers, func(p data.Peer) string { return p.ID }), want)
+}
+
+// applyPeerGroup adds or removes peers from group, group.Peers is kept up to
+// date for subsequent changes of the same group
+func (c Controller) applyPeerGroup(ctx context.Context, group *data.Group, peerIDs []string, want bool) error {
+	if group == nil {
+		return errors.New("group not found, it is created with the next sync")
+	}
+
+	var changed []string
+	for _, id := range peerIDs {
+		if slices.Contains(group.Peers, id) == want {
+			continue
+		}
+		changed = append(changed, id)
+		if want {
+			group.Peers = append(group.Peers, id)
+		} else {
+			group.Peers = slices.DeleteFunc(group.Peers, func(p string) bool { return p == id })
+		}
+	}
+	if len(changed) == 0 {
+		return nil
+	}
+
+	if want {
+		slog.Warn("Adding peers to group", "group_name", group.Name, "peers", changed)
+	} else {
+		slog.Warn("Removing peers from group", "group_name", group.Name, "peers", changed)
+	}
+	return c.netbirdClient.UpdateGroup(ctx, *group)
+}
+
+// nextTransition returns when schedule next changes in RFC3339, empty if it
+// never does or there is no schedule
+func nextTransition(schedule *data.Schedule, now time.Time) string {
+	if schedule == nil {
+		return ""
+	}
+	if t, ok := schedule.NextTransition(now); ok {
+		return t.Format(time.RFC3339)
+	}
+	return ""
+}
+
+func nextSuffix(next string) string {
+	if next == "" {
+		return ""
+	}
+	return ", next schedule transition at " + next
+}
@@ -0,0 +1,389 @@
+package controller
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/mrsool/netbird-gitops/pkg/data"
+	"github.com/nikoksr/notify"
+	"gopkg.in/yaml.v3"
+)
+
+// proposedPeer peers section entry of a peer missing from git
+type proposedPeer struct {
+	ID                 string   `yaml:"id"`
+	Name               string   `yaml:"name"`
+	Groups             []string `yaml:"groups,omitempty"`
+	SSHEnabled         bool     `yaml:"ssh_enabled,omitempty"`
+	ExpirationDisabled bool     `yaml:"expiration_disabled,omitempty"`
+}
+
+// proposedUser users section entry of a user missing from git
+type proposedUser struct {
+	Email  string   `yaml:"email"`
+	Role   string   `yaml:"role"`
+	Groups []string `yaml:"groups,omitempty"`
+}
+
+// writeBack proposes peers and users missing from git by committing them to
+// the write back branch and/or writing the commit as a patch file. The same
+// proposal is only written once
+func (c *Controller) writeBack(ctx context.Context, cfg *data.CombinedConfig) error {
+	if c.WriteBackBranch == "" && c.WriteBackPatchPath == "" {
+		return nil
+	}
+
+	peers, users, err := c.proposals(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	if len(peers) == 0 && len(users) == 0 {
+		c.lastWriteBack = ""
+		return nil
+	}
+
+	fingerprint, err := yaml.Marshal(map[string]interface{}{"peers": peers, "users": users})
+	if err != nil {
+		return err
+	}
+	if string(fingerprint) == c.lastWriteBack {
+		slog.Debug("Write back proposal unchanged, skipping")
+		return nil
+	}
+
+	dir, err := os.MkdirTemp("", "netbird-gitops-writeback")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	repo, err := git.PlainCloneContext(ctx, dir, false, &git.CloneOptions{
+		URL:           c.GitRepoURL,
+		ReferenceName: plumbing.NewBranchReferenceName(c.GitBranch),
+		RemoteName:    "origin",
+		Auth:          c.GitAuth,
+		SingleBranch:  true,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to clone repo: %w", err)
+	}
+	workTree, err := repo.Worktree()
+	if err != nil {
+		return err
+	}
+	head, err := repo.Head()
+	if err != nil {
+		return err
+	}
+
+	configDir := path.Join(dir, c.GitRelativePath)
+	sections := []struct {
+		name    string
+		entries interface{}
+		count   int
+	}{
+		{"peers", peers, len(peers)},
+		{"users", users, len(users)},
+	}
+	for _, s := range sections {
+		if s.count == 0 {
+			continue
+		}
+		file, err := appendSection(configDir, s.name, s.entries)
+		if err != nil {
+			return fmt.Errorf("failed to add %s: %w", s.name, err)
+		}
+		_, err = workTree.Add(strings.TrimPrefix(path.Join(c.GitRelativePath, file), "/"))
+		if err != nil {
+			return err
+		}
+	}
+
+	msg := fmt.Sprintf("Add %d peers and %d users missing from NetBird GitOps config", len(peers), len(users))
+	hash, err := workTree.Commit(msg, &git.CommitOptions{
+		Author: &object.Signature{Name: "netbird-gitops", Email: "netbird-gitops@localhost", When: time.Now()},
+	})
+	if err != nil {
+		return err
+	}
+
+	if c.WriteBackPatchPath != "" {
+		base, err := repo.CommitObject(head.Hash())
+		if err != nil {
+			return err
+		}
+		proposed, err := repo.CommitObject(hash)
+		if err != nil {
+			return err
+		}
+		patch, err := base.PatchContext(ctx, proposed)
+		if err != nil {
+			return err
+		}
+		err = os.WriteFile(c.WriteBackPatchPath, []byte(patch.String()), 0o644)
+		if err != nil {
+			return err
+		}
+		slog.Warn("Wrote proposal patch", "path", c.WriteBackPatchPath, "peers", len(peers), "users", len(users))
+		notify.Send(ctx, "", fmt.Sprintf("%s: patch written to %s", msg, c.WriteBackPatchPath))
+	}
+
+	if c.WriteBackBranch != "" {
+		pending, err := c.proposalPending(ctx, repo, head.Hash())
+		if err != nil {
+			return err
+		}
+		if pending {
+			slog.Warn("Proposal branch has unmerged commits, not overwriting", "branch", c.WriteBackBranch, "peers", len(peers), "users", len(users))
+			notify.Send(ctx, "", fmt.Sprintf("%s: branch %s has unmerged commits, merge or delete it to receive new proposals", msg, c.WriteBackBranch))
+		} else {
+			// The branch only holds merged commits, recreate it from the synced branch
+			err = repo.PushContext(ctx, &git.PushOptions{
+				RemoteName: "origin",
+				Auth:       c.GitAuth,
+				RefSpecs:   []config.RefSpec{config.RefSpec(fmt.Sprintf("+refs/heads/%s:refs/heads/%s", c.GitBranch, c.WriteBackBranch))},
+			})
+			if err != nil {
+				return fmt.Errorf("failed to push %s: %w", c.WriteBackBranch, err)
+			}
+			slog.Warn("Pushed proposal branch", "branch", c.WriteBackBranch, "peers", len(peers), "users", len(users))
+			notify.Send(ctx, "", fmt.Sprintf("%s: pushed to branch %s for review", msg, c.WriteBackBranch))
+		}
+	}
+
+	c.lastWriteBack = string(fingerprint)
+	return nil
+}
+
+// proposalPending returns true if the write back branch exists with commits
+// not on the synced branch at base, i.e. an unmerged proposal possibly edited
+// by a reviewer
+func (c Controller) proposalPending(ctx context.Context, repo *git.Repository, base plumbing.Hash) (bool, error) {
+	remoteRef := plumbing.NewRemoteReferenceName("origin", c.WriteBackBranch)
+	err := repo.FetchContext(ctx, &git.FetchOptions{
+		RemoteName: "origin",
+		Auth:       c.GitAuth,
+		RefSpecs:   []config.RefSpec{config.RefSpec(fmt.Sprintf("+refs/heads/%s:%s", c.WriteBackBranch, remoteRef))},
+	})
+	if errors.Is(err, git.NoMatchingRefSpecError{}) {
+		return false, nil
+	}
+	if err != nil && !errors.Is(err, git.NoErrAlreadyUpToDate) {
+		return false, fmt.Errorf("failed to fetch %s: %w", c.WriteBackBranch, err)
+	}
+
+	ref, err := repo.Reference(remoteRef, true)
+	if err != nil {
+		return false, err
+	}
+	branchHead, err := repo.CommitObject(ref.Hash())
+	if err != nil {
+		return false, err
+	}
+	baseCommit, err := repo.CommitObject(base)
+	if err != nil {
+		return false, err
+	}
+	merged, err := branchHead.IsAncestor(baseCommit)
+	return !merged, err
+}
+
+// proposals returns entries of NetBird peers and users missing from git
+func (c Controller) proposals(ctx context.Context, cfg *data.CombinedConfig) ([]proposedPeer, []proposedUser, error) {
+	peers, err := c.netbirdClient.ListPeers(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	users, err := c.netbirdClient.ListUsers(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	groups, err := c.netbirdClient.ListGroups(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	groupIDName := make(map[string]string)
+	for _, g := range groups {
+		groupIDName[g.ID] = g.Name
+	}
+	userGroups := make(map[string][]string)
+	for _, u := range users {
+		userGroups[u.ID] = u.Groups
+	}
+	derived := derivedGroups(cfg)
+
+	knownPeers := make(map[string]bool)
+	for _, gp := range cfg.Peers {
+		if p, err := data.ResolvePeer(peers, gp.ID); err == nil {
+			knownPeers[p.ID] = true
+		}
+	}
+	var retPeers []proposedPeer
+	for _, p := range peers {
+		if knownPeers[p.ID] {
+			continue
+		}
+		entry := proposedPeer{
+			ID:                 p.ID,
+			Name:               p.Name,
+			SSHEnabled:         p.SSHEnabled,
+			ExpirationDisabled: !p.LoginExpirationEnabled,
+		}
+		for _, g := range p.Groups {
+			// Memberships derived by the controller would become static
+			if g.Name == "All" || derived[g.Name] {
+				continue
+			}
+			if p.UserID != "" && !cfg.Config.IndividualPeerGroups && slices.Contains(userGroups[p.UserID], g.ID) {
+				continue
+			}
+			entry.Groups = append(entry.Groups, g.Name)
+		}
+		retPeers = append(retPeers, entry)
+	}
+
+	knownUsers := make(map[string]bool)
+	for _, u := range cfg.Users {
+		knownUsers[strings.ToLower(u.Email)] = true
+	}
+	var retUsers []proposedUser
+	for _, u := range users {
+		// Blocked users are most likely removed from git on purpose
+		if u.ServiceUser || u.Blocked || u.Email == "" || knownUsers[strings.ToLower(u.Email)] {
+			continue
+		}
+		entry := proposedUser{Email: u.Email, Role: u.GetRole()}
+		for _, g := range u.Groups {
+			entry.Groups = append(entry.Groups, groupIDName[g])
+		}
+		retUsers = append(retUsers, entry)
+	}
+
+	return retPeers, retUsers, nil
+}
+
+// derivedGroups returns names of groups whose peers are derived by the
+// controller from group definitions or quarantine
+func derivedGroups(cfg *data.CombinedConfig) map[string]bool {
+	ret := make(map[string]bool)
+	for _, g := range cfg.Groups {
+		if len(g.Groups)+len(g.Peers)+len(g.Users)+len(g.Selectors) > 0 {
+			ret[g.Name] = true
+		}
+	}
+	for _, policy := range []data.UnmanagedPeerPolicy{cfg.Config.UnmanagedPeers.SetupKeyPeers, cfg.Config.UnmanagedPeers.UserPeers} {
+		if policy.GetAction() == data.UnmanagedPeerQuarantine {
+			ret[policy.QuarantineGroup] = true
+		}
+	}
+	return ret
+}
+
+// appendSection appends entries to the list of section in the config file
+// defining it, or creates <section>.yaml. Returns the written file name
+func appendSection(dir, section string, entries interface{}) (string, error) {
+	out, err := marshalYAML(entries)
+	if err != nil {
+		return "", err
+	}
+
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		return "", err
+	}
+	for _, f := range files {
+		ext := path.Ext(f.Name())
+		if ext != ".yaml" && ext != ".yml" {
+			continue
+		}
+		fileBytes, err := os.ReadFile(path.Join(dir, f.Name()))
+		if err != nil {
+			return "", err
+		}
+
+		var doc yaml.Node
+		err = yaml.Unmarshal(fileBytes, &doc)
+		if err != nil {
+			return "", err
+		}
+		if doc.Kind != yaml.DocumentNode || doc.Content[0].Kind != yaml.MappingNode {
+			continue
+		}
+		root := doc.Content[0]
+		for idx := 0; idx < len(root.Content); idx += 2 {
+			if root.Content[idx].Value != section {
+				continue
+			}
+			seq := root.Content[idx+1]
+			last := idx+2 == len(root.Content)
+
+			// Appending text keeps formatting and comments of the file intact,
+			// only possible if the section is a non-empty block list at the end
+			if last && seq.Kind == yaml.SequenceNode && seq.Style&yaml.FlowStyle == 0 && len(seq.Content) > 0 {
+				indent := strings.Repeat(" ", max(seq.Content[0].Column-3, 0))
+				var buf bytes.Buffer
+				buf.Write(fileBytes)
+				if !bytes.HasSuffix(fileBytes, []byte("\n")) {
+					buf.WriteString("\n")
+				}
+				for _, line := range strings.SplitAfter(string(out), "\n") {
+					if line != "" {
+						buf.WriteString(indent + line)
+					}
+				}
+				return f.Name(), os.WriteFile(path.Join(dir, f.Name()), buf.Bytes(), 0o644)
+			}
+
+			var added yaml.Node
+			err = yaml.Unmarshal(out, &added)
+			if err != nil {
+				return "", err
+			}
+			if seq.Kind != yaml.SequenceNode {
+				seq.Kind, seq.Tag, seq.Value = yaml.SequenceNode, "!!seq", ""
+			}
+			seq.Style = 0
+			seq.Content = append(seq.Content, added.Content[0].Content...)
+
+			out, err = marshalYAML(&doc)
+			if err != nil {
+				return "", err
+			}
+			return f.Name(), os.WriteFile(path.Join(dir, f.Name()), out, 0o644)
+		}
+	}
+
+	name := section + ".yaml"
+	if _, err := os.Stat(path.Join(dir, name)); err == nil {
+		name = section + "-proposed.yaml"
+	}
+	out, err = marshalYAML(map[string]interface{}{section: entries})
+	if err != nil {
+		return "", err
+	}
+	return name, os.WriteFile(path.Join(dir, name), out, 0o644)
+}
+
+// marshalYAML marshals v with the 2 space indentation used in config files
+func marshalYAML(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	err := enc.Encode(v)
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), enc.Close()
+}
@@ -0,0 +1,111 @@
+package data
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// AccountSettings NetBird account-wide settings, unset fields are not managed
+type AccountSettings struct {
+	PeerLoginExpirationEnabled      *bool          `yaml:"peer_login_expiration_enabled"`
+	PeerLoginExpiration             *time.Duration `yaml:"peer_login_expiration"`
+	PeerInactivityExpirationEnabled *bool          `yaml:"peer_inactivity_expiration_enabled"`
+	PeerInactivityExpiration        *time.Duration `yaml:"peer_inactivity_expiration"`
+	PeerApprovalEnabled             *bool          `yaml:"peer_approval_enabled"`
+	GroupsPropagationEnabled        *bool          `yaml:"groups_propagation_enabled"`
+	JWTGroupsEnabled                *bool          `yaml:"jwt_groups_enabled"`
+	JWTGroupsClaimName              *string        `yaml:"jwt_groups_claim_name"`
+	JWTAllowGroups                  *[]string      `yaml:"jwt_allow_groups"`
+	RoutingPeerDNSResolutionEnabled *bool          `yaml:"routing_peer_dns_resolution_enabled"`
+}
+
+// Account NetBird account object
+type Account struct {
+	ID       string                  `json:"id"`
+	Settings AccountSettingsResponse `json:"settings"`
+	// RawSettings all settings as returned by NetBird, sent back on update so
+	// settings not modelled in AccountSettingsResponse are kept
+	RawSettings map[string]interface{} `json:"-"`
+}
+
+// AccountSettingsResponse NetBird account settings API object
+type AccountSettingsResponse struct {
+	PeerLoginExpirationEnabled      bool     `json:"peer_login_expiration_enabled"`
+	PeerLoginExpiration             int      `json:"peer_login_expiration"`
+	PeerInactivityExpirationEnabled bool     `json:"peer_inactivity_expiration_enabled"`
+	PeerInactivityExpiration        int      `json:"peer_inactivity_expiration"`
+	RegularUsersViewBlocked         bool     `json:"regular_users_view_blocked"`
+	GroupsPropagationEnabled        bool     `json:"groups_propagation_enabled"`
+	JWTGroupsEnabled                bool     `json:"jwt_groups_enabled"`
+	JWTGroupsClaimName              string   `json:"jwt_groups_claim_name"`
+	JWTAllowGroups                  []string `json:"jwt_allow_groups"`
+	RoutingPeerDNSResolutionEnabled bool     `json:"routing_peer_dns_resolution_enabled"`
+	Extra                           struct {
+		PeerApprovalEnabled bool `json:"peer_approval_enabled"`
+	} `json:"extra"`
+}
+
+// Diff returns "field: old -> new" for each managed setting differing from
+// the account's current settings
+func (s AccountSettings) Diff(cur AccountSettingsResponse) []string {
+	var diffs []string
+	diffField(&diffs, "peer_login_expiration_enabled", cur.PeerLoginExpirationEnabled, s.PeerLoginExpirationEnabled)
+	diffField(&diffs, "peer_login_expiration", seconds(cur.PeerLoginExpiration), s.PeerLoginExpiration)
+	diffField(&diffs, "peer_inactivity_expiration_enabled", cur.PeerInactivityExpirationEnabled, s.PeerInactivityExpirationEnabled)
+	diffField(&diffs, "peer_inactivity_expiration", seconds(cur.PeerInactivityExpiration), s.PeerInactivityExpiration)
+	diffField(&diffs, "peer_approval_enabled", cur.Extra.PeerApprovalEnabled, s.PeerApprovalEnabled)
+	diffField(&diffs, "groups_propagation_enabled", cur.GroupsPropagationEnabled, s.GroupsPropagationEnabled)
+	diffField(&diffs, "jwt_groups_enabled", cur.JWTGroupsEnabled, s.JWTGroupsEnabled)
+	diffField(&diffs, "jwt_groups_claim_name", cur.JWTGroupsClaimName, s.JWTGroupsClaimName)
+	diffField(&diffs, "jwt_allow_groups", cur.JWTAllowGroups, s.JWTAllowGroups)
+	diffField(&diffs, "routing_peer_dns_resolution_enabled", cur.RoutingPeerDNSResolutionEnabled, s.RoutingPeerDNSResolutionEnabled)
+	return diffs
+}
+
+// Apply sets managed settings on raw account settings, other settings are
+// left as-is
+func (s AccountSettings) Apply(raw map[string]interface{}) {
+	applyField(raw, "peer_login_expiration_enabled", s.PeerLoginExpirationEnabled)
+	if s.PeerLoginExpiration != nil {
+		raw["peer_login_expiration"] = int(s.PeerLoginExpiration.Seconds())
+	}
+	applyField(raw, "peer_inactivity_expiration_enabled", s.PeerInactivityExpirationEnabled)
+	if s.PeerInactivityExpiration != nil {
+		raw["peer_inactivity_expiration"] = int(s.PeerInactivityExpiration.Seconds())
+	}
+	if s.PeerApprovalEnabled != nil {
+		extra, ok := raw["extra"].(map[string]interface{})
+		if !ok {
+			extra = make(map[string]interface{})
+			raw["extra"] = extra
+		}
+		applyField(extra, "peer_approval_enabled", s.PeerApprovalEnabled)
+	}
+	applyField(raw, "groups_propagation_enabled", s.GroupsPropagationEnabled)
+	applyField(raw, "jwt_groups_enabled", s.JWTGroupsEnabled)
+	applyField(raw, "jwt_groups_claim_name", s.JWTGroupsClaimName)
+	applyField(raw, "jwt_allow_groups", s.JWTAllowGroups)
+	applyField(raw, "routing_peer_dns_resolution_enabled", s.RoutingPeerDNSResolutionEnabled)
+}
+
+func seconds(s int) time.Duration {
+	return time.Duration(s) * time.Second
+}
+
+func diffField[T any](diffs *[]string, name string, cur T, want *T) {
+	if want == nil || reflect.DeepEqual(cur, *want) {
+		return
+	}
+	// Treat nil and empty slices as equal
+	if v := reflect.ValueOf(cur); v.Kind() == reflect.Slice && v.Len() == 0 && reflect.ValueOf(*want).Len() == 0 {
+		return
+	}
+	*diffs = append(*diffs, fmt.Sprintf("%s: %v -> %v", name, cur, *want))
+}
+
+func applyField[T any](raw map[string]interface{}, key string, want *T) {
+	if want != nil {
+		raw[key] = *want
+	}
+}
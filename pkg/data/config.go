@@ -1,19 +1,165 @@
 package data
 
+import (
+	"time"
+
+	"github.com/mrsool/netbird-gitops/pkg/util"
+)
+
+// Unmanaged peer actions
+const (
+	UnmanagedPeerIgnore     = "ignore"
+	UnmanagedPeerRestrict   = "restrict"
+	UnmanagedPeerQuarantine = "quarantine"
+	UnmanagedPeerDelete     = "delete"
+)
+
+// Removed user actions
+const (
+	RemovedUserIgnore          = "ignore"
+	RemovedUserBlock           = "block"
+	RemovedUserDelete          = "delete"
+	RemovedUserBlockThenDelete = "blockThenDelete"
+)
+
+// Peer approval modes
+const (
+	PeerApprovalManual = "manual"
+	PeerApprovalGit    = "git"
+)
+
 // Config holds program configuration
 type Config struct {
-	AutoSync             string `yaml:"autoSync"`
-	IndividualPeerGroups bool   `yaml:"individualPeerGroups"`
+	AutoSync             string            `yaml:"autoSync"`
+	IndividualPeerGroups bool              `yaml:"individualPeerGroups"`
+	UnmanagedPeers       UnmanagedPeers    `yaml:"unmanagedPeers"`
+	InviteTimeout        time.Duration     `yaml:"inviteTimeout"`
+	RemovedUsers         RemovedUserPolicy `yaml:"removedUsers"`
+	SSODeletedUsers      RemovedUserPolicy `yaml:"ssoDeletedUsers"`
+	AdditionalRoles      []string          `yaml:"additionalRoles"`
+	PeerApproval         string            `yaml:"peerApproval"`
+	ExpiryNotice         time.Duration     `yaml:"expiryNotice"`
+}
+
+// RemovedUserPolicy action taken on a user that exists in NetBird but not in
+// git, or was deleted from SSO
+type RemovedUserPolicy struct {
+	Action      string        `yaml:"action"`
+	DeleteAfter time.Duration `yaml:"deleteAfter"`
+}
+
+// GetAction returns action, def if unset
+func (r RemovedUserPolicy) GetAction(def string) string {
+	if r.Action == "" {
+		return def
+	}
+	return r.Action
+}
+
+// UnmanagedPeers handling of peers that exist in NetBird but not in git, per
+// peer class
+type UnmanagedPeers struct {
+	SetupKeyPeers UnmanagedPeerPolicy `yaml:"setupKeyPeers"`
+	UserPeers     UnmanagedPeerPolicy `yaml:"userPeers"`
+}
+
+// UnmanagedPeerPolicy action taken on a peer missing from git
+type UnmanagedPeerPolicy struct {
+	Action          string        `yaml:"action"`
+	QuarantineGroup string        `yaml:"quarantineGroup"`
+	GracePeriod     time.Duration `yaml:"gracePeriod"`
+}
+
+// For returns policy applicable to peer p
+func (u UnmanagedPeers) For(p Peer) UnmanagedPeerPolicy {
+	if p.UserID == "" {
+		return u.SetupKeyPeers
+	}
+	return u.UserPeers
+}
+
+// GetAction returns action, restrict by default
+func (u UnmanagedPeerPolicy) GetAction() string {
+	if u.Action == "" {
+		return UnmanagedPeerRestrict
+	}
+	return u.Action
 }
 
 // CombinedConfig combined config of all files
 type CombinedConfig struct {
-	Config        Config         `yaml:"config"`
-	Nameservers   []Nameserver   `yaml:"nameservers"`
-	DNS           DNS            `yaml:"dns"`
-	Peers         []Peer         `yaml:"peers"`
-	Policies      []Policy       `yaml:"policies"`
-	PostureChecks []PostureCheck `yaml:"posture_checks"`
-	NetworkRoutes []NetworkRoute `yaml:"network_routes"`
-	Users         []User         `yaml:"users"`
+	Config          Config            `yaml:"config"`
+	Groups          []GroupDefinition `yaml:"groups"`
+	AccountSettings *AccountSettings  `yaml:"account_settings"`
+	Nameservers     []Nameserver      `yaml:"nameservers"`
+	DNS             *DNS              `yaml:"dns"`
+	DNSZones        []DNSZone         `yaml:"dns_zones"`
+	Peers           []Peer            `yaml:"peers"`
+	Policies        []Policy          `yaml:"policies"`
+	PostureChecks   []PostureCheck    `yaml:"posture_checks"`
+	NetworkRoutes   []NetworkRoute    `yaml:"network_routes"`
+	ExitNodes       []ExitNode        `yaml:"exit_nodes"`
+	Networks        []Network         `yaml:"networks"`
+	Users           []User            `yaml:"users"`
+	ServiceUsers    []ServiceUser     `yaml:"service_users"`
+	SetupKeys       []SetupKey        `yaml:"setup_keys"`
+}
+
+// ReferencedGroups returns names of all groups referenced by configuration,
+// excluding groups declared in the groups section
+func (c CombinedConfig) ReferencedGroups() []string {
+	var ret []string
+	if c.DNS != nil {
+		ret = append(ret, c.DNS.GetDisableFor()...)
+	}
+	for _, ns := range c.Nameservers {
+		ret = append(ret, ns.Groups...)
+	}
+	for _, zone := range c.DNSZones {
+		ret = append(ret, zone.DistributionGroups...)
+	}
+	for _, route := range c.NetworkRoutes {
+		ret = append(ret, route.Groups...)
+		ret = append(ret, route.PeerGroups...)
+		ret = append(ret, route.AccessControlGroups...)
+	}
+	for _, network := range c.Networks {
+		for _, resource := range network.Resources {
+			ret = append(ret, resource.GroupNames...)
+		}
+		for _, router := range network.Routers {
+			ret = append(ret, router.PeerGroups...)
+		}
+	}
+	for _, peer := range c.Peers {
+		ret = append(ret, peer.GroupNames...)
+		for _, g := range peer.TemporaryGroups {
+			ret = append(ret, g.Group)
+		}
+	}
+	for _, policy := range c.Policies {
+		ret = append(ret, policy.Sources...)
+		ret = append(ret, policy.Destinations...)
+	}
+	for _, user := range c.Users {
+		ret = append(ret, user.Groups...)
+		for _, g := range user.TemporaryGroups {
+			ret = append(ret, g.Group)
+		}
+	}
+	for _, user := range c.ServiceUsers {
+		ret = append(ret, user.Groups...)
+	}
+	for _, key := range c.SetupKeys {
+		ret = append(ret, key.AutoGroups...)
+	}
+	for _, group := range c.Groups {
+		ret = append(ret, group.Groups...)
+	}
+	for _, policy := range []UnmanagedPeerPolicy{c.Config.UnmanagedPeers.SetupKeyPeers, c.Config.UnmanagedPeers.UserPeers} {
+		if policy.GetAction() == UnmanagedPeerQuarantine {
+			ret = append(ret, policy.QuarantineGroup)
+		}
+	}
+	return util.Unique(ret)
 }
@@ -8,14 +8,18 @@ import (
 
 // DNS holds NetBird DNS Management settings
 type DNS struct {
-	DisableFor []string `yaml:"disableFor" json:"disabled_management_groups"`
+	DisableFor []string `yaml:"disable_for" json:"disabled_management_groups"`
+	// LegacyDisableFor deprecated camelCase key, use DisableFor
+	LegacyDisableFor []string `yaml:"disableFor" json:"-"`
 }
 
-// DNSResponse holds NetBird DNS Management Settings Response
-type DNSResponse struct {
-	Items struct {
-		DisableFor []string `json:"disabled_management_groups"`
-	} `json:"items"`
+// GetDisableFor returns groups with DNS management disabled, falling back to
+// the deprecated disableFor key
+func (d DNS) GetDisableFor() []string {
+	if d.DisableFor == nil {
+		return d.LegacyDisableFor
+	}
+	return d.DisableFor
 }
 
 // Nameserver holds one nameserver group settings
@@ -38,10 +42,9 @@ type NameserverServer struct {
 	Port   uint   `yaml:"port" json:"port"`
 }
 
-// Equals == operator
+// Equals == operator, IDs are not compared as git nameservers have none
 func (ns Nameserver) Equals(o Nameserver) bool {
-	return ns.ID == o.ID &&
-		ns.Name == o.Name &&
+	return ns.Name == o.Name &&
 		ns.Description == o.Description &&
 		slices.EqualFunc(ns.Nameservers, o.Nameservers, func(a, b NameserverServer) bool {
 			return a.IP == b.IP && a.NSType == b.NSType && a.Port == b.Port
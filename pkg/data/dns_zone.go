@@ -0,0 +1,74 @@
+package data
+
+import (
+	"net/netip"
+	"strings"
+
+	"github.com/mrsool/netbird-gitops/pkg/util"
+)
+
+// DNS record types supported in custom zones
+const (
+	DNSRecordA     = "A"
+	DNSRecordAAAA  = "AAAA"
+	DNSRecordCNAME = "CNAME"
+)
+
+// DNSZone NetBird custom DNS zone distributed to groups
+type DNSZone struct {
+	ID                 string      `yaml:"-" json:"id"`
+	Name               string      `yaml:"name" json:"name"`
+	Domain             string      `yaml:"domain" json:"domain"`
+	Enabled            bool        `yaml:"enabled" json:"enabled"`
+	EnableSearchDomain bool        `yaml:"enable_search_domain" json:"enable_search_domain"`
+	DistributionGroups []string    `yaml:"distribution_groups" json:"distribution_groups"`
+	Records            []DNSRecord `yaml:"records" json:"-"`
+}
+
+// DNSRecord record of a custom DNS zone, name is fully qualified
+type DNSRecord struct {
+	ID      string `yaml:"-" json:"id"`
+	Name    string `yaml:"name" json:"name"`
+	Type    string `yaml:"type" json:"type"`
+	Content string `yaml:"content" json:"content"`
+	TTL     int    `yaml:"ttl" json:"ttl"`
+}
+
+// Equals returns if zones are equal, records are not compared
+func (z DNSZone) Equals(o DNSZone) bool {
+	return z.Name == o.Name &&
+		NormalizeDomain(z.Domain) == NormalizeDomain(o.Domain) &&
+		z.Enabled == o.Enabled &&
+		z.EnableSearchDomain == o.EnableSearchDomain &&
+		util.SortedEqual(z.DistributionGroups, o.DistributionGroups)
+}
+
+// GetTTL returns record TTL in seconds, 300 by default
+func (r DNSRecord) GetTTL() int {
+	if r.TTL == 0 {
+		return 300
+	}
+	return r.TTL
+}
+
+// Key identifies record within a zone, records with the same name and type
+// but different content are kept side by side
+func (r DNSRecord) Key() string {
+	content := r.Content
+	switch r.Type {
+	case DNSRecordCNAME:
+		content = NormalizeDomain(content)
+	case DNSRecordA, DNSRecordAAAA:
+		// Addresses are compared in canonical form, e.g. FD00:0::1 as fd00::1
+		if addr, err := netip.ParseAddr(content); err == nil {
+			content = addr.String()
+		}
+	}
+	return NormalizeDomain(r.Name) + " " + r.Type + " " + content
+}
+
+// NormalizeDomain returns domain in lower case without trailing dot, the form
+// NetBird returns domains in
+func NormalizeDomain(domain string) string {
+	return strings.ToLower(strings.TrimSuffix(domain, "."))
+}
@@ -0,0 +1,63 @@
+package data
+
+// Default route prefixes
+const (
+	DefaultRouteIPv4 = "0.0.0.0/0"
+	DefaultRouteIPv6 = "::/0"
+)
+
+// ExitNode routing peer or peer group carrying internet traffic of groups
+type ExitNode struct {
+	Name          string   `yaml:"name"`
+	Description   string   `yaml:"description"`
+	Enabled       bool     `yaml:"enabled"`
+	Peer          string   `yaml:"peer"`
+	PeerGroups    []string `yaml:"peer_groups"`
+	Groups        []string `yaml:"groups"`
+	Masquerade    bool     `yaml:"masquerade"`
+	Priority      int      `yaml:"priority"`
+	IPv6          bool     `yaml:"ipv6"`
+	SkipAutoApply bool     `yaml:"skip_auto_apply"`
+}
+
+// GetMetric returns route metric, lower priority wins. Defaults to 9999
+func (e ExitNode) GetMetric() int {
+	if e.Priority == 0 {
+		return 9999
+	}
+	return e.Priority
+}
+
+// Routes returns default routes of the exit node, ::/0 is only included if
+// IPv6 is set
+func (e ExitNode) Routes() []NetworkRoute {
+	route := NetworkRoute{
+		NetworkType:   "IPv4",
+		Description:   e.Description,
+		NetworkID:     e.Name,
+		Enabled:       e.Enabled,
+		Peer:          e.Peer,
+		PeerGroups:    e.PeerGroups,
+		Network:       DefaultRouteIPv4,
+		Metric:        e.GetMetric(),
+		Masquerade:    e.Masquerade,
+		Groups:        e.Groups,
+		SkipAutoApply: e.SkipAutoApply,
+	}
+	ret := []NetworkRoute{route}
+
+	if e.IPv6 {
+		route.NetworkType = "IPv6"
+		route.NetworkID = e.Name + "-v6"
+		route.Network = DefaultRouteIPv6
+		ret = append(ret, route)
+	}
+	return ret
+}
+
+// ExpandExitNodes appends routes of exit nodes to network routes
+func (c *CombinedConfig) ExpandExitNodes() {
+	for _, e := range c.ExitNodes {
+		c.NetworkRoutes = append(c.NetworkRoutes, e.Routes()...)
+	}
+}
@@ -1,5 +1,15 @@
 package data
 
+import (
+	"errors"
+	"fmt"
+	"net/netip"
+	"regexp"
+	"strings"
+
+	"github.com/mrsool/netbird-gitops/pkg/util"
+)
+
 // Group mapping of group ID and name
 type Group struct {
 	Name     string   `json:"name"`
@@ -7,3 +17,151 @@ type Group struct {
 	Peers    []string `json:"-"`
 	PeerData []Peer   `json:"peers"`
 }
+
+// GroupDefinition git-declared group, optionally composed of other groups,
+// peers, users' peers and peers matching selectors. Declared groups are never
+// pruned
+type GroupDefinition struct {
+	Name      string         `yaml:"name"`
+	Groups    []string       `yaml:"groups"`
+	Peers     []string       `yaml:"peers"`
+	Users     []string       `yaml:"users"`
+	Selectors []PeerSelector `yaml:"selectors"`
+}
+
+// PeerSelector matches peers by their attributes, a peer matches if all set
+// fields match. Regexes must match the whole value
+type PeerSelector struct {
+	Name         string `yaml:"name"`          // Regex on peer name
+	Hostname     string `yaml:"hostname"`      // Regex on peer hostname
+	OS           string `yaml:"os"`            // Regex on peer OS
+	Version      string `yaml:"version"`       // NetBird version constraint, e.g. ">=0.28.0"
+	IP           string `yaml:"ip"`            // NetBird IP or CIDR
+	ConnectionIP string `yaml:"connection_ip"` // Connection IP or CIDR
+	Country      string `yaml:"country"`       // Connection IP country code
+	EmailDomain  string `yaml:"email_domain"`  // Owning user's email domain
+
+	// compiled Name, Hostname and OS regexes, set by Validate
+	compiled [3]*regexp.Regexp
+}
+
+// Validate checks selector fields are well-formed and compiles the regexes
+func (s *PeerSelector) Validate() error {
+	if *s == (PeerSelector{}) {
+		return errors.New("empty selector")
+	}
+	for idx, re := range s.regexes() {
+		compiled, err := compileSelectorRegex(re)
+		if err != nil {
+			return err
+		}
+		s.compiled[idx] = compiled
+	}
+	if s.Version != "" {
+		if _, err := util.VersionSatisfies("0", s.Version); err != nil {
+			return err
+		}
+	}
+	for _, prefix := range []string{s.IP, s.ConnectionIP} {
+		if prefix == "" {
+			continue
+		}
+		if _, err := parsePrefix(prefix); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Matches returns true if peer p owned by user with ownerEmail matches selector
+func (s PeerSelector) Matches(p Peer, ownerEmail string) bool {
+	for idx, v := range []string{p.Name, p.Hostname, p.OS} {
+		if !s.matchRegex(idx, v) {
+			return false
+		}
+	}
+	if s.Version != "" {
+		if ok, err := util.VersionSatisfies(p.Version, s.Version); err != nil || !ok {
+			return false
+		}
+	}
+	if !matchPrefix(s.IP, p.IP) || !matchPrefix(s.ConnectionIP, p.ConnectionIP) {
+		return false
+	}
+	if s.Country != "" && !strings.EqualFold(s.Country, p.CountryCode) {
+		return false
+	}
+	if s.EmailDomain != "" {
+		_, domain, ok := strings.Cut(ownerEmail, "@")
+		if !ok || !strings.EqualFold(strings.TrimPrefix(s.EmailDomain, "@"), domain) {
+			return false
+		}
+	}
+	return true
+}
+
+func (s PeerSelector) regexes() [3]string {
+	return [3]string{s.Name, s.Hostname, s.OS}
+}
+
+// matchRegex matches v against the idx-th regex, compiling it if the selector
+// wasn't validated
+func (s PeerSelector) matchRegex(idx int, v string) bool {
+	re := s.regexes()[idx]
+	if re == "" {
+		return true
+	}
+	compiled := s.compiled[idx]
+	if compiled == nil {
+		var err error
+		compiled, err = compileSelectorRegex(re)
+		if err != nil {
+			return false
+		}
+	}
+	return compiled.MatchString(v)
+}
+
+// compileSelectorRegex compiles re anchored to match the whole value, nil if
+// re is empty
+func compileSelectorRegex(re string) (*regexp.Regexp, error) {
+	if re == "" {
+		return nil, nil
+	}
+	return regexp.Compile("^(?:" + re + ")$")
+}
+
+func matchPrefix(prefix, ip string) bool {
+	if prefix == "" {
+		return true
+	}
+	p, err := parsePrefix(prefix)
+	if err != nil {
+		return false
+	}
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		addrPort, err := netip.ParseAddrPort(ip)
+		if err != nil {
+			return false
+		}
+		addr = addrPort.Addr()
+	}
+	return p.Contains(addr)
+}
+
+// parsePrefix parses CIDR or a single IP address as a prefix
+func parsePrefix(s string) (netip.Prefix, error) {
+	if !strings.Contains(s, "/") {
+		addr, err := netip.ParseAddr(s)
+		if err != nil {
+			return netip.Prefix{}, fmt.Errorf("invalid IP %q: %w", s, err)
+		}
+		return netip.PrefixFrom(addr, addr.BitLen()), nil
+	}
+	p, err := netip.ParsePrefix(s)
+	if err != nil {
+		return netip.Prefix{}, fmt.Errorf("invalid CIDR %q: %w", s, err)
+	}
+	return p, nil
+}
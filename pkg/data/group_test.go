@@ -0,0 +1,77 @@
+package data
+
+import "testing"
+
+func TestPeerSelectorValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		sel     PeerSelector
+		wantErr bool
+	}{
+		{"empty", PeerSelector{}, true},
+		{"regex", PeerSelector{Name: "eng-.*"}, false},
+		{"invalid regex", PeerSelector{Hostname: "("}, true},
+		{"version", PeerSelector{Version: ">=0.28.0"}, false},
+		{"unknown operator", PeerSelector{Version: "~0.28"}, true},
+		{"non-numeric version", PeerSelector{Version: ">=abc"}, true},
+		{"ip", PeerSelector{IP: "100.64.0.1"}, false},
+		{"cidr", PeerSelector{ConnectionIP: "203.0.113.0/24"}, false},
+		{"invalid cidr", PeerSelector{IP: "100.64.0.0/33"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.sel.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPeerSelectorMatches(t *testing.T) {
+	peer := Peer{
+		Name:         "eng-laptop-1",
+		Hostname:     "alice-macbook",
+		OS:           "Darwin 14.4.1",
+		Version:      "0.28.4",
+		IP:           "100.64.0.10",
+		ConnectionIP: "203.0.113.5:51820",
+		CountryCode:  "DE",
+	}
+	tests := []struct {
+		name  string
+		sel   PeerSelector
+		email string
+		want  bool
+	}{
+		{"name", PeerSelector{Name: "eng-.*"}, "", true},
+		{"name anchored", PeerSelector{Name: "laptop"}, "", false},
+		{"name alternation anchored", PeerSelector{Name: "foo|eng-laptop-1"}, "", true},
+		{"os case insensitive", PeerSelector{OS: "(?i)darwin.*"}, "", true},
+		{"os mismatch", PeerSelector{OS: "Linux.*"}, "", false},
+		{"version", PeerSelector{Version: ">=0.28.0"}, "", true},
+		{"version too old", PeerSelector{Version: ">0.28.4"}, "", false},
+		{"ip", PeerSelector{IP: "100.64.0.0/16"}, "", true},
+		{"ip mismatch", PeerSelector{IP: "100.65.0.0/16"}, "", false},
+		{"connection ip with port", PeerSelector{ConnectionIP: "203.0.113.0/24"}, "", true},
+		{"country", PeerSelector{Country: "de"}, "", true},
+		{"email domain", PeerSelector{EmailDomain: "@Example.com"}, "alice@example.com", true},
+		{"email domain mismatch", PeerSelector{EmailDomain: "example.com"}, "alice@example.org", false},
+		{"email domain no owner", PeerSelector{EmailDomain: "example.com"}, "", false},
+		{"all fields", PeerSelector{Name: "eng-.*", Country: "DE", Version: "<1.0"}, "", true},
+		{"one field mismatch", PeerSelector{Name: "eng-.*", Country: "US"}, "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.sel.Matches(peer, tt.email); got != tt.want {
+				t.Errorf("Matches() = %v, want %v", got, tt.want)
+			}
+			// Validated selectors use the compiled regexes
+			if err := tt.sel.Validate(); err != nil {
+				t.Fatal(err)
+			}
+			if got := tt.sel.Matches(peer, tt.email); got != tt.want {
+				t.Errorf("Matches() after Validate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
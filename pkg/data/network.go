@@ -0,0 +1,69 @@
+package data
+
+import (
+	"strings"
+
+	"github.com/mrsool/netbird-gitops/pkg/util"
+)
+
+// Network NetBird network containing resources and routing peers
+type Network struct {
+	ID          string            `yaml:"-" json:"id"`
+	Name        string            `yaml:"name" json:"name"`
+	Description string            `yaml:"description" json:"description"`
+	Resources   []NetworkResource `yaml:"resources" json:"-"`
+	Routers     []NetworkRouter   `yaml:"routers" json:"-"`
+}
+
+// NetworkResource host, subnet or domain reachable through a network
+type NetworkResource struct {
+	ID          string   `yaml:"-" json:"id"`
+	Type        string   `yaml:"-" json:"type"`
+	Name        string   `yaml:"name" json:"name"`
+	Description string   `yaml:"description" json:"description"`
+	Address     string   `yaml:"address" json:"address"`
+	Enabled     bool     `yaml:"enabled" json:"enabled"`
+	Groups      []Group  `yaml:"-" json:"groups"`
+	GroupNames  []string `yaml:"groups" json:"-"`
+}
+
+// NetworkRouter routing peer or peer groups of a network
+type NetworkRouter struct {
+	ID         string   `yaml:"-" json:"id"`
+	Peer       string   `yaml:"peer" json:"peer"`
+	PeerGroups []string `yaml:"peer_groups" json:"peer_groups"`
+	Metric     int      `yaml:"metric" json:"metric"`
+	Masquerade bool     `yaml:"masquerade" json:"masquerade"`
+	Enabled    bool     `yaml:"enabled" json:"enabled"`
+}
+
+// Equals returns if network resources are equal, comparing group IDs
+func (r NetworkResource) Equals(o NetworkResource) bool {
+	return r.Name == o.Name &&
+		r.Description == o.Description &&
+		r.Address == o.Address &&
+		r.Enabled == o.Enabled &&
+		util.SortedEqual(util.Map(r.Groups, func(g Group) string { return g.ID }), util.Map(o.Groups, func(g Group) string { return g.ID }))
+}
+
+// Key identifies router within a network by its peer or peer groups
+func (r NetworkRouter) Key() string {
+	if r.Peer != "" {
+		return r.Peer
+	}
+	return strings.Join(util.Unique(r.PeerGroups), ",")
+}
+
+// Equals returns if network routers are equal
+func (r NetworkRouter) Equals(o NetworkRouter) bool {
+	return r.Peer == o.Peer &&
+		util.SortedEqual(r.PeerGroups, o.PeerGroups) &&
+		r.Metric == o.Metric &&
+		r.Masquerade == o.Masquerade &&
+		r.Enabled == o.Enabled
+}
+
+// ResourceRef returns policy reference of a network resource
+func ResourceRef(network, resource string) string {
+	return network + "/" + resource
+}
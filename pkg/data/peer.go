@@ -1,13 +1,88 @@
 package data
 
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrPeerNotFound returned when a peer reference matches no peer
+var ErrPeerNotFound = errors.New("peer not found")
+
 // Peer associates a peer with 0+ groups
 type Peer struct {
-	ID                     string   `yaml:"id" json:"id"`
-	Name                   string   `yaml:"name" json:"name"`
-	Groups                 []Group  `yaml:"-" json:"groups"`
-	GroupNames             []string `yaml:"groups"`
-	SSHEnabled             bool     `yaml:"ssh_enabled" json:"ssh_enabled"`
-	ExpirationDisabled     bool     `yaml:"expiration_disabled"`
-	LoginExpirationEnabled bool     `json:"login_expiration_enabled"`
-	UserID                 string   `json:"user_id"`
+	ID                          string           `yaml:"id" json:"id"`
+	Name                        string           `yaml:"name" json:"name"`
+	Groups                      []Group          `yaml:"-" json:"groups"`
+	GroupNames                  []string         `yaml:"groups"`
+	SSHEnabled                  bool             `yaml:"ssh_enabled" json:"ssh_enabled"`
+	ExpirationDisabled          bool             `yaml:"expiration_disabled"`
+	LoginExpirationEnabled      bool             `json:"login_expiration_enabled"`
+	InactivityExpirationEnabled *bool            `yaml:"inactivity_expiration_enabled" json:"inactivity_expiration_enabled"`
+	ApprovalRequired            *bool            `yaml:"approval_required" json:"approval_required"`
+	UserID                      string           `json:"user_id"`
+	Hostname                    string           `yaml:"-" json:"hostname"`
+	OS                          string           `yaml:"-" json:"os"`
+	Version                     string           `yaml:"-" json:"version"`
+	IP                          string           `yaml:"-" json:"ip"`
+	ConnectionIP                string           `yaml:"-" json:"connection_ip"`
+	CountryCode                 string           `yaml:"-" json:"country_code"`
+	DNSLabel                    string           `yaml:"-" json:"dns_label"`
+	LastSeen                    time.Time        `yaml:"-" json:"last_seen"`
+	Connected                   bool             `yaml:"-" json:"connected"`
+	TemporaryGroups             []TemporaryGroup `yaml:"temporary_groups" json:"-"`
+}
+
+// GetApprovalRequired returns the approval state wanted by git for a peer
+// currently in state cur. If unset in git, peers are approved in git approval
+// mode and left untouched otherwise. Peers without approval state are left
+// untouched, the account doesn't support approval and NetBird rejects the field
+func (p Peer) GetApprovalRequired(cur *bool, mode string) *bool {
+	if cur == nil {
+		return nil
+	}
+	if p.ApprovalRequired != nil {
+		return p.ApprovalRequired
+	}
+	if mode == PeerApprovalGit {
+		approved := false
+		return &approved
+	}
+	return cur
+}
+
+// GetInactivityExpirationEnabled returns the inactivity expiration wanted by
+// git for a peer currently in state cur, left untouched if unset in git
+func (p Peer) GetInactivityExpirationEnabled(cur *bool) *bool {
+	if p.InactivityExpirationEnabled != nil {
+		return p.InactivityExpirationEnabled
+	}
+	return cur
+}
+
+// ResolvePeer finds the peer referenced by ref, which is either a peer ID or a
+// peer's name, hostname or DNS label. Non-ID references must be unambiguous
+func ResolvePeer(peers []Peer, ref string) (Peer, error) {
+	var matches []Peer
+	for _, p := range peers {
+		if p.ID == ref {
+			return p, nil
+		}
+		if p.Name == ref || p.Hostname == ref || p.DNSLabel == ref {
+			matches = append(matches, p)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return Peer{}, fmt.Errorf("%w: %s", ErrPeerNotFound, ref)
+	case 1:
+		return matches[0], nil
+	}
+
+	var ids []string
+	for _, p := range matches {
+		ids = append(ids, p.ID)
+	}
+	return Peer{}, fmt.Errorf("ambiguous peer reference %s matches peers %v", ref, ids)
 }
@@ -2,6 +2,7 @@ package data
 
 import (
 	"errors"
+	"time"
 
 	"github.com/mrsool/netbird-gitops/pkg/util"
 )
@@ -10,18 +11,27 @@ import (
 
 // Policy holds NetBird ACL Policy object
 type Policy struct {
-	ID                  string       `json:"id"`
-	Name                string       `yaml:"name" json:"name"`
-	Enabled             bool         `yaml:"enabled" json:"enabled"`
-	Description         string       `yaml:"description" json:"description"`
-	SourcePostureChecks []string     `yaml:"source_posture_checks"`
-	Action              string       `yaml:"action"`
-	Bidirectional       bool         `yaml:"bidirectional"`
-	Protocol            string       `yaml:"protocol"`
-	Sources             []string     `yaml:"sources"`
-	Destinations        []string     `yaml:"destinations"`
-	Rules               []PolicyRule `json:"rules"`
-	Ports               []string     `yaml:"ports"`
+	ID                      string       `json:"id"`
+	Name                    string       `yaml:"name" json:"name"`
+	Enabled                 bool         `yaml:"enabled" json:"enabled"`
+	Description             string       `yaml:"description" json:"description"`
+	SourcePostureChecks     []string     `yaml:"source_posture_checks" json:"source_posture_checks"`
+	Action                  string       `yaml:"action"`
+	Bidirectional           bool         `yaml:"bidirectional"`
+	Protocol                string       `yaml:"protocol"`
+	Sources                 []string     `yaml:"sources"`
+	Destinations            []string     `yaml:"destinations"`
+	Rules                   []PolicyRule `json:"rules"`
+	Ports                   []string     `yaml:"ports"`
+	DestinationResource     string       `yaml:"destination_resource"`
+	DestinationResourceType string       `yaml:"-"`
+	Schedule                *Schedule    `yaml:"schedule" json:"-"`
+	Validity                `yaml:",inline"`
+}
+
+// ActiveAt returns if the policy is within its validity and schedule at now
+func (p Policy) ActiveAt(now time.Time) bool {
+	return p.Active(now) && (p.Schedule == nil || p.Schedule.Active(now))
 }
 
 // PolicyRule Policy.Rules section
@@ -33,6 +43,14 @@ type PolicyRule struct {
 	Bidirectional     bool     `json:"bidirectional"`
 	Protocol          string   `json:"protocol"`
 	Ports             []string `json:"ports"`
+	// DestinationResource set if the rule targets a network resource
+	DestinationResource *PolicyResource `json:"destinationResource"`
+}
+
+// PolicyResource network resource reference in a policy rule
+type PolicyResource struct {
+	ID   string `json:"id"`
+	Type string `json:"type"`
 }
 
 // Equals == operator
@@ -45,7 +63,8 @@ func (p Policy) Equals(o Policy) bool {
 		p.Bidirectional == o.Bidirectional &&
 		p.Protocol == o.Protocol &&
 		util.SortedEqual(p.Sources, o.Sources) &&
-		util.SortedEqual(p.Destinations, o.Destinations)
+		util.SortedEqual(p.Destinations, o.Destinations) &&
+		p.DestinationResource == o.DestinationResource
 }
 
 // Flatten converts uselessly nested policy rule to policy object
@@ -63,5 +82,9 @@ func (p *Policy) Flatten() error {
 	p.Ports = p.Rules[0].Ports
 	p.Sources = util.Map(p.Rules[0].SourceGroups, func(g Group) string { return g.ID })
 	p.Destinations = util.Map(p.Rules[0].DestinationGroups, func(g Group) string { return g.ID })
+	if p.Rules[0].DestinationResource != nil {
+		p.DestinationResource = p.Rules[0].DestinationResource.ID
+		p.DestinationResourceType = p.Rules[0].DestinationResource.Type
+	}
 	return nil
 }
@@ -1,5 +1,22 @@
 package data
 
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/mrsool/netbird-gitops/pkg/util"
+)
+
+// Posture check actions of geo location and network range checks
+const (
+	PostureCheckAllow = "allow"
+	PostureCheckDeny  = "deny"
+	// PostureCheckBlock deprecated alias of PostureCheckDeny
+	PostureCheckBlock = "block"
+)
+
 // PostureCheck holds NetBird PostureCheck object
 type PostureCheck struct {
 	ID          string              `json:"id"`
@@ -8,22 +25,168 @@ type PostureCheck struct {
 	Checks      PostureCheckDetails `yaml:"checks" json:"checks"`
 }
 
-// PostureCheckDetails different checks in posture check
+// Equals returns if posture checks are equal after normalization
+func (p PostureCheck) Equals(o PostureCheck) bool {
+	return p.Name == o.Name && len(p.Diff(o)) == 0
+}
+
+// Normalize returns a copy with empty checks removed and unordered lists
+// sorted, so checks read from git and NetBird compare equal
+func (p PostureCheck) Normalize() PostureCheck {
+	checks := p.Checks
+	p.Checks = PostureCheckDetails{}
+
+	if checks.NBVersionCheck != nil && checks.NBVersionCheck.MinVersion != "" {
+		p.Checks.NBVersionCheck = &MinVersionDescriptor{MinVersion: checks.NBVersionCheck.MinVersion}
+	}
+
+	if osCheck := checks.OSVersionCheck; osCheck != nil {
+		version := func(v string) *MinVersionDescriptor {
+			if v == "" {
+				return nil
+			}
+			return &MinVersionDescriptor{MinVersion: v}
+		}
+		kernelVersion := func(v string) *MinKernelVersionDescriptor {
+			if v == "" {
+				return nil
+			}
+			return &MinKernelVersionDescriptor{MinKernelVersion: v}
+		}
+		normalized := OSVersionCheckObj{
+			Android: version(minVersion(osCheck.Android)),
+			IOS:     version(minVersion(osCheck.IOS)),
+			Darwin:  version(minVersion(osCheck.Darwin)),
+			Linux:   kernelVersion(minKernelVersion(osCheck.Linux)),
+			Windows: kernelVersion(minKernelVersion(osCheck.Windows)),
+		}
+		if normalized != (OSVersionCheckObj{}) {
+			p.Checks.OSVersionCheck = &normalized
+		}
+	}
+
+	if geo := checks.GeoLocationCheck; geo != nil && (len(geo.Locations) > 0 || geo.Action != "") {
+		locations := slices.Clone(geo.Locations)
+		slices.SortFunc(locations, func(a, b GeoLocation) int {
+			return strings.Compare(a.CountryCode+"/"+a.CityName, b.CountryCode+"/"+b.CityName)
+		})
+		p.Checks.GeoLocationCheck = &GeoLocationCheckObj{Locations: locations, Action: postureCheckAction(geo.Action)}
+	}
+
+	if nr := checks.PeerNetworkRangeCheck; nr != nil && (len(nr.Ranges) > 0 || nr.Action != "") {
+		p.Checks.PeerNetworkRangeCheck = &PeerNetworkRangeCheckObj{Ranges: util.Unique(nr.Ranges), Action: postureCheckAction(nr.Action)}
+	}
+
+	if pc := checks.ProcessCheck; pc != nil && len(pc.Processes) > 0 {
+		p.Checks.ProcessCheck = &ProcessCheckObj{Processes: slices.Clone(pc.Processes)}
+	}
+
+	return p
+}
+
+// Diff returns changes from p to o as "field: old -> new" entries, versions
+// are compared numerically
+func (p PostureCheck) Diff(o PostureCheck) []string {
+	cur, want := p.Normalize(), o.Normalize()
+	var diffs []string
+
+	if cur.Description != want.Description {
+		diffs = append(diffs, fmt.Sprintf("description: %s -> %s", cur.Description, want.Description))
+	}
+
+	diffVersion(&diffs, "nb_version_check.min_version", minVersion(cur.Checks.NBVersionCheck), minVersion(want.Checks.NBVersionCheck))
+
+	curOS, wantOS := cur.Checks.OSVersionCheck, want.Checks.OSVersionCheck
+	if curOS == nil {
+		curOS = &OSVersionCheckObj{}
+	}
+	if wantOS == nil {
+		wantOS = &OSVersionCheckObj{}
+	}
+	diffVersion(&diffs, "os_version_check.android.min_version", minVersion(curOS.Android), minVersion(wantOS.Android))
+	diffVersion(&diffs, "os_version_check.ios.min_version", minVersion(curOS.IOS), minVersion(wantOS.IOS))
+	diffVersion(&diffs, "os_version_check.darwin.min_version", minVersion(curOS.Darwin), minVersion(wantOS.Darwin))
+	diffVersion(&diffs, "os_version_check.linux.min_kernel_version", minKernelVersion(curOS.Linux), minKernelVersion(wantOS.Linux))
+	diffVersion(&diffs, "os_version_check.windows.min_kernel_version", minKernelVersion(curOS.Windows), minKernelVersion(wantOS.Windows))
+
+	diffCheck(&diffs, "geo_location_check", cur.Checks.GeoLocationCheck, want.Checks.GeoLocationCheck)
+	diffCheck(&diffs, "peer_network_range_check", cur.Checks.PeerNetworkRangeCheck, want.Checks.PeerNetworkRangeCheck)
+	diffCheck(&diffs, "process_check", cur.Checks.ProcessCheck, want.Checks.ProcessCheck)
+
+	return diffs
+}
+
+// postureCheckAction maps the deprecated block action to deny
+func postureCheckAction(action string) string {
+	if action == PostureCheckBlock {
+		return PostureCheckDeny
+	}
+	return action
+}
+
+// UsesBlockAction returns if the deprecated block action is used
+func (p PostureCheck) UsesBlockAction() bool {
+	geo, nr := p.Checks.GeoLocationCheck, p.Checks.PeerNetworkRangeCheck
+	return (geo != nil && geo.Action == PostureCheckBlock) || (nr != nil && nr.Action == PostureCheckBlock)
+}
+
+func minVersion(d *MinVersionDescriptor) string {
+	if d == nil {
+		return ""
+	}
+	return d.MinVersion
+}
+
+func minKernelVersion(d *MinKernelVersionDescriptor) string {
+	if d == nil {
+		return ""
+	}
+	return d.MinKernelVersion
+}
+
+func diffVersion(diffs *[]string, name, cur, want string) {
+	if cur == want || (cur != "" && want != "" && util.CompareVersions(cur, want) == 0) {
+		return
+	}
+	*diffs = append(*diffs, fmt.Sprintf("%s: %s -> %s", name, orNone(cur), orNone(want)))
+}
+
+func diffCheck[T any](diffs *[]string, name string, cur, want *T) {
+	if reflect.DeepEqual(cur, want) {
+		return
+	}
+	format := func(v *T) string {
+		if v == nil {
+			return "none"
+		}
+		return fmt.Sprintf("%+v", *v)
+	}
+	*diffs = append(*diffs, fmt.Sprintf("%s: %s -> %s", name, format(cur), format(want)))
+}
+
+func orNone(s string) string {
+	if s == "" {
+		return "none"
+	}
+	return s
+}
+
+// PostureCheckDetails different checks in posture check, nil checks are not set
 type PostureCheckDetails struct {
-	NBVersionCheck        MinVersionDescriptor     `yaml:"nb_version_check" json:"nb_version_check"`
-	OSVersionCheck        OSVersionCheckObj        `yaml:"os_version_check" json:"os_version_check"`
-	GeoLocationCheck      GeoLocationCheckObj      `yaml:"geo_location_check" json:"geo_location_check"`
-	PeerNetworkRangeCheck PeerNetworkRangeCheckObj `yaml:"peer_network_range_check" json:"peer_network_range_check"`
-	ProcessCheck          ProcessCheckObj          `yaml:"process_check" json:"process_check"`
+	NBVersionCheck        *MinVersionDescriptor     `yaml:"nb_version_check" json:"nb_version_check,omitempty"`
+	OSVersionCheck        *OSVersionCheckObj        `yaml:"os_version_check" json:"os_version_check,omitempty"`
+	GeoLocationCheck      *GeoLocationCheckObj      `yaml:"geo_location_check" json:"geo_location_check,omitempty"`
+	PeerNetworkRangeCheck *PeerNetworkRangeCheckObj `yaml:"peer_network_range_check" json:"peer_network_range_check,omitempty"`
+	ProcessCheck          *ProcessCheckObj          `yaml:"process_check" json:"process_check,omitempty"`
 }
 
-// OSVersionCheckObj Different OS types version checks
+// OSVersionCheckObj Different OS types version checks, nil OSes are not checked
 type OSVersionCheckObj struct {
-	Android MinVersionDescriptor       `yaml:"android" json:"android"`
-	IOS     MinVersionDescriptor       `yaml:"ios" json:"ios"`
-	Darwin  MinVersionDescriptor       `yaml:"darwin" json:"darwin"`
-	Linux   MinKernelVersionDescriptor `yaml:"linux" json:"linux"`
-	Windows MinKernelVersionDescriptor `yaml:"windows" json:"windows"`
+	Android *MinVersionDescriptor       `yaml:"android" json:"android,omitempty"`
+	IOS     *MinVersionDescriptor       `yaml:"ios" json:"ios,omitempty"`
+	Darwin  *MinVersionDescriptor       `yaml:"darwin" json:"darwin,omitempty"`
+	Linux   *MinKernelVersionDescriptor `yaml:"linux" json:"linux,omitempty"`
+	Windows *MinKernelVersionDescriptor `yaml:"windows" json:"windows,omitempty"`
 }
 
 // MinVersionDescriptor descriptor for generic min version
@@ -39,17 +202,19 @@ type MinKernelVersionDescriptor struct {
 // GeoLocationCheckObj posture check geo location check
 type GeoLocationCheckObj struct {
 	Locations []GeoLocation `yaml:"locations" json:"locations"`
+	Action    string        `yaml:"action" json:"action"`
 }
 
 // GeoLocation descriptor for a geolocation
 type GeoLocation struct {
 	CountryCode string `yaml:"country_code" json:"country_code"`
-	CityName    string `yaml:"city_name" json:"city_name"`
+	CityName    string `yaml:"city_name" json:"city_name,omitempty"`
 }
 
 // PeerNetworkRangeCheckObj posture check network range check
 type PeerNetworkRangeCheckObj struct {
 	Ranges []string `yaml:"ranges" json:"ranges"`
+	Action string   `yaml:"action" json:"action"`
 }
 
 // ProcessCheckObj posture check process checklist
@@ -59,7 +224,7 @@ type ProcessCheckObj struct {
 
 // OSProcess posture check for different paths for OS
 type OSProcess struct {
-	LinuxPath   string `yaml:"linux_path" json:"linux_path"`
-	MacPath     string `yaml:"mac_path" json:"mac_path"`
-	WindowsPath string `yaml:"windows_path" json:"windows_path"`
+	LinuxPath   string `yaml:"linux_path" json:"linux_path,omitempty"`
+	MacPath     string `yaml:"mac_path" json:"mac_path,omitempty"`
+	WindowsPath string `yaml:"windows_path" json:"windows_path,omitempty"`
 }
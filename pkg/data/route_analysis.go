@@ -0,0 +1,88 @@
+package data
+
+import (
+	"fmt"
+	"net/netip"
+	"strings"
+
+	"github.com/mrsool/netbird-gitops/pkg/util"
+)
+
+// analyzeRoutes checks network routes against each other. Duplicate network
+// IDs are errors since routes are matched by them, overlapping prefixes,
+// HA routes with equal metrics and shadowing domains are warnings
+func (c CombinedConfig) analyzeRoutes() (warnings []string, errs []error) {
+	ids := make(map[string]bool)
+	for _, r := range c.NetworkRoutes {
+		if ids[r.NetworkID] {
+			errs = append(errs, fmt.Errorf("network_routes: duplicate network_id %s", r.NetworkID))
+		}
+		ids[r.NetworkID] = true
+	}
+
+	routes := util.Select(c.NetworkRoutes, func(r NetworkRoute) bool { return r.Enabled })
+	for i, a := range routes {
+		for _, b := range routes[i+1:] {
+			groups := sharedGroups(a.Groups, b.Groups)
+			if len(groups) == 0 {
+				continue
+			}
+			on := strings.Join(groups, ", ")
+
+			if a.Network != "" && b.Network != "" {
+				pa, errA := netip.ParsePrefix(a.Network)
+				pb, errB := netip.ParsePrefix(b.Network)
+				if errA != nil || errB != nil || !pa.Overlaps(pb) {
+					continue
+				}
+				if pa.Masked() != pb.Masked() {
+					// Default routes overlap everything, longest prefix match
+					// makes that intentional
+					if pa.Bits() == 0 || pb.Bits() == 0 {
+						continue
+					}
+					warnings = append(warnings, fmt.Sprintf("network_routes: %s (%s) overlaps %s (%s) for groups %s", a.NetworkID, a.Network, b.NetworkID, b.Network, on))
+					continue
+				}
+				// Default routes with equal metrics are rejected by validateExitNodes
+				if a.Metric == b.Metric && a.Network != DefaultRouteIPv4 && a.Network != DefaultRouteIPv6 {
+					warnings = append(warnings, fmt.Sprintf("network_routes: %s and %s route %s with the same metric %d for groups %s", a.NetworkID, b.NetworkID, a.Network, a.Metric, on))
+				}
+				continue
+			}
+
+			for _, da := range a.Domains {
+				for _, db := range b.Domains {
+					if domainShadows(da, db) || domainShadows(db, da) {
+						warnings = append(warnings, fmt.Sprintf("network_routes: domain %s of %s shadows domain %s of %s for groups %s", da, a.NetworkID, db, b.NetworkID, on))
+					}
+				}
+			}
+		}
+	}
+
+	return warnings, errs
+}
+
+// sharedGroups returns groups present in both a and b
+func sharedGroups(a, b []string) []string {
+	return util.Unique(util.Select(a, func(g string) bool {
+		for _, o := range b {
+			if g == o {
+				return true
+			}
+		}
+		return false
+	}))
+}
+
+// domainShadows returns if domain a also matches domain b, either being equal
+// or a being a wildcard covering b
+func domainShadows(a, b string) bool {
+	a, b = strings.ToLower(strings.TrimSuffix(a, ".")), strings.ToLower(strings.TrimSuffix(b, "."))
+	if a == b {
+		return true
+	}
+	suffix, ok := strings.CutPrefix(a, "*")
+	return ok && strings.HasSuffix(b, suffix)
+}
@@ -0,0 +1,154 @@
+package data
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Schedule recurring weekly windows a policy is enabled in
+type Schedule struct {
+	Timezone string           `yaml:"timezone"`
+	Windows  []ScheduleWindow `yaml:"windows"`
+}
+
+// ScheduleWindow daily time window on the given days, every day if no days
+// are set. A window ending before its start spans midnight
+type ScheduleWindow struct {
+	Days  []string `yaml:"days"`
+	Start string   `yaml:"start"`
+	End   string   `yaml:"end"`
+}
+
+// Validate checks timezone, days and times of the schedule
+func (s Schedule) Validate() error {
+	if _, err := time.LoadLocation(s.Timezone); err != nil {
+		return fmt.Errorf("invalid timezone: %w", err)
+	}
+	if len(s.Windows) == 0 {
+		return errors.New("at least one window is required")
+	}
+	var errs []error
+	for idx, w := range s.Windows {
+		for _, d := range w.Days {
+			if _, ok := parseWeekday(d); !ok {
+				errs = append(errs, fmt.Errorf("windows[%d]: invalid day %q", idx, d))
+			}
+		}
+		if _, err := parseClock(w.Start); err != nil {
+			errs = append(errs, fmt.Errorf("windows[%d]: invalid start: %w", idx, err))
+		}
+		if _, err := parseClock(w.End); err != nil {
+			errs = append(errs, fmt.Errorf("windows[%d]: invalid end: %w", idx, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Active returns if now is within one of the windows
+func (s Schedule) Active(now time.Time) bool {
+	local := now.In(s.location())
+	for _, w := range s.Windows {
+		if w.active(local) {
+			return true
+		}
+	}
+	return false
+}
+
+// NextTransition returns when the schedule next becomes active or inactive,
+// false if it never changes
+func (s Schedule) NextTransition(now time.Time) (time.Time, bool) {
+	loc := s.location()
+	local := now.In(loc)
+	var candidates []time.Time
+	// Transitions only happen at window boundaries, a week covers all of them
+	for d := -1; d <= 8; d++ {
+		day := local.AddDate(0, 0, d)
+		for _, w := range s.Windows {
+			for _, clock := range []string{w.Start, w.End} {
+				m, err := parseClock(clock)
+				if err != nil {
+					continue
+				}
+				t := time.Date(day.Year(), day.Month(), day.Day(), 0, m, 0, 0, loc)
+				if t.After(now) {
+					candidates = append(candidates, t)
+				}
+			}
+		}
+	}
+	slices.SortFunc(candidates, func(a, b time.Time) int { return a.Compare(b) })
+
+	cur := s.Active(now)
+	for _, t := range candidates {
+		if s.Active(t) != cur {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+func (s Schedule) location() *time.Location {
+	loc, err := time.LoadLocation(s.Timezone)
+	if err != nil {
+		// Rejected by Validate
+		return time.UTC
+	}
+	return loc
+}
+
+func (w ScheduleWindow) active(local time.Time) bool {
+	start, errStart := parseClock(w.Start)
+	end, errEnd := parseClock(w.End)
+	if errStart != nil || errEnd != nil {
+		return false
+	}
+	minute := local.Hour()*60 + local.Minute()
+	if start < end {
+		return w.onDay(local.Weekday()) && minute >= start && minute < end
+	}
+	// Spans midnight, the part after midnight belongs to the previous day
+	return (w.onDay(local.Weekday()) && minute >= start) ||
+		(w.onDay((local.Weekday()+6)%7) && minute < end)
+}
+
+func (w ScheduleWindow) onDay(day time.Weekday) bool {
+	if len(w.Days) == 0 {
+		return true
+	}
+	for _, d := range w.Days {
+		if wd, ok := parseWeekday(d); ok && wd == day {
+			return true
+		}
+	}
+	return false
+}
+
+// parseWeekday parses day names like mon or Monday
+func parseWeekday(s string) (time.Weekday, bool) {
+	s = strings.ToLower(s)
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		name := strings.ToLower(d.String())
+		if s == name || s == name[:3] {
+			return d, true
+		}
+	}
+	return 0, false
+}
+
+// parseClock parses HH:MM into minutes since midnight, 24:00 is allowed as
+// end of day
+func parseClock(s string) (int, error) {
+	var h, m int
+	_, err := fmt.Sscanf(s, "%d:%d", &h, &m)
+	if err != nil || len(s) != 5 {
+		return 0, fmt.Errorf("%q is not HH:MM", s)
+	}
+	if h < 0 || m < 0 || m > 59 || h > 24 || (h == 24 && m != 0) {
+		return 0, fmt.Errorf("%q is out of range", s)
+	}
+	return h*60 + m, nil
+}
@@ -0,0 +1,103 @@
+package data
+
+import (
+	"testing"
+	"time"
+)
+
+func TestScheduleValidate(t *testing.T) {
+	window := []ScheduleWindow{{Start: "09:00", End: "17:00"}}
+	tests := []struct {
+		name    string
+		s       Schedule
+		wantErr bool
+	}{
+		{"valid", Schedule{Timezone: "Europe/Berlin", Windows: window}, false},
+		{"empty timezone is UTC", Schedule{Windows: window}, false},
+		{"invalid timezone", Schedule{Timezone: "Mars/Olympus", Windows: window}, true},
+		{"no windows", Schedule{Timezone: "UTC"}, true},
+		{"day names", Schedule{Windows: []ScheduleWindow{{Days: []string{"mon", "Tuesday", "SUN"}, Start: "09:00", End: "17:00"}}}, false},
+		{"invalid day", Schedule{Windows: []ScheduleWindow{{Days: []string{"mo"}, Start: "09:00", End: "17:00"}}}, true},
+		{"end of day", Schedule{Windows: []ScheduleWindow{{Start: "22:00", End: "24:00"}}}, false},
+		{"past end of day", Schedule{Windows: []ScheduleWindow{{Start: "22:00", End: "24:01"}}}, true},
+		{"invalid minute", Schedule{Windows: []ScheduleWindow{{Start: "09:60", End: "17:00"}}}, true},
+		{"short clock", Schedule{Windows: []ScheduleWindow{{Start: "9:00", End: "17:00"}}}, true},
+		{"not a clock", Schedule{Windows: []ScheduleWindow{{Start: "09:00", End: "5pm"}}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.s.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestScheduleActive(t *testing.T) {
+	weekdays := []string{"mon", "tue", "wed", "thu", "fri"}
+	// 2026-10-19 is a Monday
+	utc := func(day, hour, min int) time.Time {
+		return time.Date(2026, 10, day, hour, min, 0, 0, time.UTC)
+	}
+	tests := []struct {
+		name string
+		s    Schedule
+		now  time.Time
+		want bool
+	}{
+		{"inside window", Schedule{Windows: []ScheduleWindow{{Days: weekdays, Start: "09:00", End: "17:00"}}}, utc(19, 9, 0), true},
+		{"end is exclusive", Schedule{Windows: []ScheduleWindow{{Days: weekdays, Start: "09:00", End: "17:00"}}}, utc(19, 17, 0), false},
+		{"wrong day", Schedule{Windows: []ScheduleWindow{{Days: weekdays, Start: "09:00", End: "17:00"}}}, utc(24, 12, 0), false},
+		{"no days is every day", Schedule{Windows: []ScheduleWindow{{Start: "09:00", End: "17:00"}}}, utc(25, 12, 0), true},
+		{"until end of day", Schedule{Windows: []ScheduleWindow{{Start: "22:00", End: "24:00"}}}, utc(19, 23, 59), true},
+		{"end of day excludes midnight", Schedule{Windows: []ScheduleWindow{{Start: "22:00", End: "24:00"}}}, utc(20, 0, 0), false},
+		{"spans midnight before", Schedule{Windows: []ScheduleWindow{{Days: []string{"fri"}, Start: "22:00", End: "06:00"}}}, utc(23, 23, 0), true},
+		{"spans midnight after", Schedule{Windows: []ScheduleWindow{{Days: []string{"fri"}, Start: "22:00", End: "06:00"}}}, utc(24, 5, 59), true},
+		{"spans midnight belongs to start day", Schedule{Windows: []ScheduleWindow{{Days: []string{"fri"}, Start: "22:00", End: "06:00"}}}, utc(23, 5, 0), false},
+		{"timezone", Schedule{Timezone: "Europe/Berlin", Windows: []ScheduleWindow{{Start: "09:00", End: "17:00"}}}, utc(19, 7, 30), true},
+		{"second window", Schedule{Windows: []ScheduleWindow{{Start: "09:00", End: "12:00"}, {Start: "13:00", End: "17:00"}}}, utc(19, 13, 0), true},
+		{"between windows", Schedule{Windows: []ScheduleWindow{{Start: "09:00", End: "12:00"}, {Start: "13:00", End: "17:00"}}}, utc(19, 12, 30), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.s.Active(tt.now); got != tt.want {
+				t.Errorf("Active(%s) = %t, want %t", tt.now, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScheduleNextTransition(t *testing.T) {
+	utc := func(month time.Month, day, hour, min int) time.Time {
+		return time.Date(2026, month, day, hour, min, 0, 0, time.UTC)
+	}
+	tests := []struct {
+		name   string
+		s      Schedule
+		now    time.Time
+		want   time.Time
+		wantOK bool
+	}{
+		{"start", Schedule{Windows: []ScheduleWindow{{Start: "09:00", End: "17:00"}}}, utc(10, 19, 8, 0), utc(10, 19, 9, 0), true},
+		{"end", Schedule{Windows: []ScheduleWindow{{Start: "09:00", End: "17:00"}}}, utc(10, 19, 9, 0), utc(10, 19, 17, 0), true},
+		{"next day", Schedule{Windows: []ScheduleWindow{{Start: "09:00", End: "17:00"}}}, utc(10, 19, 17, 0), utc(10, 20, 9, 0), true},
+		{"after weekend", Schedule{Windows: []ScheduleWindow{{Days: []string{"mon", "tue", "wed", "thu", "fri"}, Start: "09:00", End: "17:00"}}}, utc(10, 23, 18, 0), utc(10, 26, 9, 0), true},
+		{"spans midnight", Schedule{Windows: []ScheduleWindow{{Days: []string{"fri"}, Start: "22:00", End: "06:00"}}}, utc(10, 23, 23, 0), utc(10, 24, 6, 0), true},
+		{"adjacent windows merge", Schedule{Windows: []ScheduleWindow{{Start: "09:00", End: "12:00"}, {Start: "12:00", End: "17:00"}}}, utc(10, 19, 10, 0), utc(10, 19, 17, 0), true},
+		{"end of day", Schedule{Windows: []ScheduleWindow{{Start: "22:00", End: "24:00"}}}, utc(10, 19, 23, 0), utc(10, 20, 0, 0), true},
+		{"always active", Schedule{Windows: []ScheduleWindow{{Start: "00:00", End: "24:00"}}}, utc(10, 19, 12, 0), time.Time{}, false},
+		{"timezone", Schedule{Timezone: "Europe/Berlin", Windows: []ScheduleWindow{{Start: "09:00", End: "17:00"}}}, utc(10, 19, 6, 0), utc(10, 19, 7, 0), true},
+		// Clocks in Berlin go back from 03:00 CEST to 02:00 CET on 2026-10-25,
+		// so the window lasts an hour longer
+		{"dst start", Schedule{Timezone: "Europe/Berlin", Windows: []ScheduleWindow{{Days: []string{"sun"}, Start: "01:00", End: "04:00"}}}, utc(10, 24, 22, 0), utc(10, 24, 23, 0), true},
+		{"dst end", Schedule{Timezone: "Europe/Berlin", Windows: []ScheduleWindow{{Days: []string{"sun"}, Start: "01:00", End: "04:00"}}}, utc(10, 24, 23, 0), utc(10, 25, 3, 0), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := tt.s.NextTransition(tt.now)
+			if ok != tt.wantOK || !got.Equal(tt.want) {
+				t.Errorf("NextTransition(%s) = %s, %t, want %s, %t", tt.now, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package data
+
+import "time"
+
+// Setup key types
+const (
+	SetupKeyReusable = "reusable"
+	SetupKeyOneOff   = "one-off"
+)
+
+// SetupKey NetBird setup key
+type SetupKey struct {
+	ID         string           `yaml:"-" json:"id"`
+	Name       string           `yaml:"name" json:"name"`
+	Type       string           `yaml:"type" json:"type"`
+	ExpiresIn  time.Duration    `yaml:"expires_in" json:"-"`
+	Expires    time.Time        `yaml:"-" json:"expires"`
+	UsageLimit int              `yaml:"usage_limit" json:"usage_limit"`
+	AutoGroups []string         `yaml:"auto_groups" json:"auto_groups"`
+	Ephemeral  bool             `yaml:"ephemeral" json:"ephemeral"`
+	Revoked    bool             `yaml:"revoked" json:"revoked"`
+	Rotation   SetupKeyRotation `yaml:"rotation" json:"-"`
+	Key        string           `yaml:"-" json:"key"`
+}
+
+// SetupKeyRotation schedule for replacing a setup key with a new one
+type SetupKeyRotation struct {
+	// Interval age after which a new key is created, rotation is disabled if unset
+	Interval time.Duration `yaml:"interval"`
+	// Overlap period both keys are valid before the old one is revoked
+	Overlap time.Duration `yaml:"overlap"`
+}
+
+// GetType returns key type, reusable by default
+func (k SetupKey) GetType() string {
+	if k.Type == "" {
+		return SetupKeyReusable
+	}
+	return k.Type
+}
+
+// EstimatedCreation returns key creation time derived from its expiry and
+// expiresIn, as NetBird doesn't expose creation time
+func (k SetupKey) EstimatedCreation(expiresIn time.Duration) time.Time {
+	return k.Expires.Add(-expiresIn)
+}
@@ -1,20 +1,71 @@
 package data
 
+import "time"
+
 // User NetBird User to groups mapping
 type User struct {
-	Email       string   `yaml:"email" json:"email"`
-	Groups      []string `yaml:"groups" json:"auto_groups"`
-	ID          string   `json:"id"`
-	Role        string   `yaml:"role" json:"role"`
-	Blocked     bool     `json:"is_blocked"`
-	ServiceUser bool     `json:"is_service_user"`
+	Email           string           `yaml:"email" json:"email"`
+	Groups          []string         `yaml:"groups" json:"auto_groups"`
+	ID              string           `json:"id"`
+	Name            string           `yaml:"-" json:"name"`
+	Role            string           `yaml:"role" json:"role"`
+	Blocked         bool             `json:"is_blocked"`
+	ServiceUser     bool             `json:"is_service_user"`
+	Status          string           `yaml:"-" json:"status"`
+	LastLogin       time.Time        `yaml:"-" json:"last_login"`
+	TemporaryGroups []TemporaryGroup `yaml:"temporary_groups" json:"-"`
+}
+
+// UserStatusInvited status of users invited but not yet onboarded
+const UserStatusInvited = "invited"
+
+// ServiceUser NetBird service user and its personal access tokens
+type ServiceUser struct {
+	Name   string                `yaml:"name"`
+	Role   string                `yaml:"role"`
+	Groups []string              `yaml:"groups"`
+	Tokens []PersonalAccessToken `yaml:"tokens"`
+}
+
+// PersonalAccessToken NetBird personal access token
+type PersonalAccessToken struct {
+	ID             string        `yaml:"-" json:"id"`
+	Name           string        `yaml:"name" json:"name"`
+	ExpiresIn      time.Duration `yaml:"expires_in" json:"-"`
+	RotateBefore   time.Duration `yaml:"rotate_before" json:"-"`
+	Overlap        time.Duration `yaml:"overlap" json:"-"`
+	ExpirationDate time.Time     `yaml:"-" json:"expiration_date"`
+	CreatedAt      time.Time     `yaml:"-" json:"created_at"`
 }
 
-// GetRole returns role if valid, user otherwise
+// NetBird user roles
+const (
+	RoleOwner        = "owner"
+	RoleAdmin        = "admin"
+	RoleUser         = "user"
+	RoleAuditor      = "auditor"
+	RoleNetworkAdmin = "network_admin"
+	RoleBillingAdmin = "billing_admin"
+)
+
+// Roles known NetBird user roles, config.additionalRoles extends this set
+var Roles = []string{RoleOwner, RoleAdmin, RoleUser, RoleAuditor, RoleNetworkAdmin, RoleBillingAdmin}
+
+// GetRole returns role, user if unset
 func (u User) GetRole() string {
-	if u.Role != "admin" && u.Role != "user" && u.Role != "owner" {
-		return "user"
+	if u.Role == "" {
+		return RoleUser
 	}
 
 	return u.Role
 }
+
+// GetRole returns role, user if unset
+func (s ServiceUser) GetRole() string {
+	return User{Role: s.Role}.GetRole()
+}
+
+// ExpiresInDays returns token lifetime in whole days as expected by NetBird
+func (t PersonalAccessToken) ExpiresInDays() int {
+	return int((t.ExpiresIn + 24*time.Hour - 1) / (24 * time.Hour))
+}
@@ -0,0 +1,581 @@
+package data
+
+import (
+	"errors"
+	"fmt"
+	"net/netip"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/mrsool/netbird-gitops/pkg/util"
+)
+
+// Validate checks configuration for errors that would make syncing unsafe,
+// warnings are returned for valid but suspicious configuration
+func (c CombinedConfig) Validate() (warnings []string, err error) {
+	var errs []error
+
+	errs = append(errs, c.validateGroups()...)
+	errs = append(errs, c.validateUnmanagedPeers()...)
+	errs = append(errs, c.validateServiceUsers()...)
+	errs = append(errs, c.validateRemovedUsers()...)
+	errs = append(errs, c.validateRoles()...)
+	errs = append(errs, c.validateSetupKeys()...)
+	errs = append(errs, c.validateAccountSettings()...)
+	errs = append(errs, c.validateNetworkRoutes()...)
+	errs = append(errs, c.validateExitNodes()...)
+	errs = append(errs, c.validateNetworks()...)
+	errs = append(errs, c.validatePostureChecks()...)
+	errs = append(errs, c.validateDNS()...)
+	errs = append(errs, c.validateDNSZones()...)
+	warnings = append(warnings, c.undeclaredGroupWarnings()...)
+	if c.Config.PeerApproval == PeerApprovalGit && c.AccountSettings != nil &&
+		c.AccountSettings.PeerApprovalEnabled != nil && !*c.AccountSettings.PeerApprovalEnabled {
+		warnings = append(warnings, "config.peerApproval is git but account_settings.peer_approval_enabled is false, new peers are not held for approval")
+	}
+	if c.DNS != nil && c.DNS.LegacyDisableFor != nil {
+		warnings = append(warnings, "dns: disableFor is deprecated, use disable_for")
+	}
+	for _, p := range c.PostureChecks {
+		if p.UsesBlockAction() {
+			warnings = append(warnings, fmt.Sprintf("posture_checks: %s: action block is deprecated, use deny", p.Name))
+		}
+	}
+
+	timeBoundWarnings, timeBoundErrs := c.validateTimeBound()
+	warnings = append(warnings, timeBoundWarnings...)
+	errs = append(errs, timeBoundErrs...)
+
+	routeWarnings, routeErrs := c.analyzeRoutes()
+	warnings = append(warnings, routeWarnings...)
+	errs = append(errs, routeErrs...)
+
+	return warnings, errors.Join(errs...)
+}
+
+// undeclaredGroupWarnings warns about referenced groups missing from the groups
+// section, which usually indicates a typo. Skipped if no groups are declared
+func (c CombinedConfig) undeclaredGroupWarnings() []string {
+	if len(c.Groups) == 0 {
+		return nil
+	}
+	var warnings []string
+	declared := util.SliceToMap(c.Groups, func(g GroupDefinition) string { return g.Name })
+	for _, g := range c.ReferencedGroups() {
+		if _, ok := declared[g]; !ok && g != "All" {
+			warnings = append(warnings, fmt.Sprintf("group %s is referenced but not declared in groups", g))
+		}
+	}
+	return warnings
+}
+
+func (c CombinedConfig) validateGroups() []error {
+	var errs []error
+	defs := make(map[string]GroupDefinition)
+	for _, g := range c.Groups {
+		if g.Name == "" {
+			errs = append(errs, errors.New("groups: group with empty name"))
+			continue
+		}
+		if _, ok := defs[g.Name]; ok {
+			errs = append(errs, fmt.Errorf("groups: duplicate group %s", g.Name))
+		}
+		defs[g.Name] = g
+		// Selectors share the backing array with c, compiled regexes are kept
+		for idx := range g.Selectors {
+			if err := g.Selectors[idx].Validate(); err != nil {
+				errs = append(errs, fmt.Errorf("groups: %s selectors[%d]: %w", g.Name, idx, err))
+			}
+		}
+	}
+
+	// Detect cycles in group composition
+	const (
+		visiting = 1
+		visited  = 2
+	)
+	state := make(map[string]int)
+	var visit func(name string, path []string) error
+	visit = func(name string, path []string) error {
+		switch state[name] {
+		case visiting:
+			return fmt.Errorf("groups: cyclic group composition %v", append(path, name))
+		case visited:
+			return nil
+		}
+		state[name] = visiting
+		for _, g := range defs[name].Groups {
+			if err := visit(g, append(path, name)); err != nil {
+				return err
+			}
+		}
+		state[name] = visited
+		return nil
+	}
+	for _, g := range c.Groups {
+		if err := visit(g.Name, nil); err != nil {
+			errs = append(errs, err)
+			break
+		}
+	}
+
+	return errs
+}
+
+func (c CombinedConfig) validateUnmanagedPeers() []error {
+	var errs []error
+	policies := map[string]UnmanagedPeerPolicy{
+		"setupKeyPeers": c.Config.UnmanagedPeers.SetupKeyPeers,
+		"userPeers":     c.Config.UnmanagedPeers.UserPeers,
+	}
+	for k, policy := range policies {
+		switch policy.GetAction() {
+		case UnmanagedPeerIgnore, UnmanagedPeerRestrict:
+		case UnmanagedPeerQuarantine:
+			if policy.QuarantineGroup == "" {
+				errs = append(errs, fmt.Errorf("config.unmanagedPeers.%s: quarantineGroup is required for action quarantine", k))
+			}
+		case UnmanagedPeerDelete:
+			if policy.GracePeriod <= 0 {
+				errs = append(errs, fmt.Errorf("config.unmanagedPeers.%s: gracePeriod must be positive for action delete", k))
+			}
+		default:
+			errs = append(errs, fmt.Errorf("config.unmanagedPeers.%s: unknown action %s", k, policy.Action))
+		}
+	}
+
+	switch c.Config.PeerApproval {
+	case "", PeerApprovalManual, PeerApprovalGit:
+	default:
+		errs = append(errs, fmt.Errorf("config.peerApproval: unknown mode %s", c.Config.PeerApproval))
+	}
+	return errs
+}
+
+func (c CombinedConfig) validateServiceUsers() []error {
+	var errs []error
+	names := make(map[string]bool)
+	for _, u := range c.ServiceUsers {
+		if u.Name == "" {
+			errs = append(errs, errors.New("service_users: service user with empty name"))
+			continue
+		}
+		if names[u.Name] {
+			errs = append(errs, fmt.Errorf("service_users: duplicate service user %s", u.Name))
+		}
+		names[u.Name] = true
+
+		tokenNames := make(map[string]bool)
+		for _, t := range u.Tokens {
+			if t.Name == "" {
+				errs = append(errs, fmt.Errorf("service_users: %s: token with empty name", u.Name))
+				continue
+			}
+			if tokenNames[t.Name] {
+				errs = append(errs, fmt.Errorf("service_users: %s: duplicate token %s", u.Name, t.Name))
+			}
+			tokenNames[t.Name] = true
+			if days := t.ExpiresInDays(); days < 1 || days > 365 {
+				errs = append(errs, fmt.Errorf("service_users: %s: token %s: expires_in must be between 1 and 365 days", u.Name, t.Name))
+			}
+			if t.RotateBefore < 0 || t.RotateBefore >= t.ExpiresIn {
+				errs = append(errs, fmt.Errorf("service_users: %s: token %s: rotate_before must be less than expires_in", u.Name, t.Name))
+			}
+			if t.Overlap < 0 {
+				errs = append(errs, fmt.Errorf("service_users: %s: token %s: overlap must not be negative", u.Name, t.Name))
+			}
+		}
+	}
+	return errs
+}
+
+func (c CombinedConfig) validateRemovedUsers() []error {
+	var errs []error
+	switch c.Config.RemovedUsers.GetAction(RemovedUserBlock) {
+	case RemovedUserIgnore, RemovedUserBlock, RemovedUserDelete:
+	case RemovedUserBlockThenDelete:
+		if c.Config.RemovedUsers.DeleteAfter <= 0 {
+			errs = append(errs, errors.New("config.removedUsers: deleteAfter is required for action blockThenDelete"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("config.removedUsers: unknown action %s", c.Config.RemovedUsers.Action))
+	}
+
+	// Users deleted from SSO have no email and cannot be blocked
+	switch c.Config.SSODeletedUsers.GetAction(RemovedUserIgnore) {
+	case RemovedUserIgnore, RemovedUserDelete:
+	default:
+		errs = append(errs, fmt.Errorf("config.ssoDeletedUsers: unsupported action %s, must be ignore or delete", c.Config.SSODeletedUsers.Action))
+	}
+	return errs
+}
+
+func (c CombinedConfig) validateRoles() []error {
+	var errs []error
+	roles := append(slices.Clone(Roles), c.Config.AdditionalRoles...)
+	for _, u := range c.Users {
+		if !slices.Contains(roles, u.GetRole()) {
+			errs = append(errs, fmt.Errorf("users: %s: unknown role %s", u.Email, u.Role))
+		}
+	}
+	for _, u := range c.ServiceUsers {
+		if !slices.Contains(roles, u.GetRole()) {
+			errs = append(errs, fmt.Errorf("service_users: %s: unknown role %s", u.Name, u.Role))
+		}
+	}
+	return errs
+}
+
+func (c CombinedConfig) validateSetupKeys() []error {
+	var errs []error
+	names := make(map[string]bool)
+	for _, k := range c.SetupKeys {
+		if k.Name == "" {
+			errs = append(errs, errors.New("setup_keys: setup key with empty name"))
+			continue
+		}
+		if names[k.Name] {
+			errs = append(errs, fmt.Errorf("setup_keys: duplicate setup key %s", k.Name))
+		}
+		names[k.Name] = true
+		if t := k.GetType(); t != SetupKeyReusable && t != SetupKeyOneOff {
+			errs = append(errs, fmt.Errorf("setup_keys: %s: unknown type %s", k.Name, k.Type))
+		}
+		if k.ExpiresIn < 0 {
+			errs = append(errs, fmt.Errorf("setup_keys: %s: expires_in must not be negative", k.Name))
+		}
+		if k.UsageLimit < 0 {
+			errs = append(errs, fmt.Errorf("setup_keys: %s: usage_limit must not be negative", k.Name))
+		}
+		if k.Rotation.Interval > 0 && k.ExpiresIn < k.Rotation.Interval+k.Rotation.Overlap {
+			errs = append(errs, fmt.Errorf("setup_keys: %s: expires_in must be at least rotation interval + overlap", k.Name))
+		}
+		if k.Rotation.Interval < 0 || k.Rotation.Overlap < 0 {
+			errs = append(errs, fmt.Errorf("setup_keys: %s: rotation interval and overlap must not be negative", k.Name))
+		}
+	}
+	return errs
+}
+
+func (c CombinedConfig) validateAccountSettings() []error {
+	if c.AccountSettings == nil {
+		return nil
+	}
+	var errs []error
+	if d := c.AccountSettings.PeerLoginExpiration; d != nil && (*d < time.Hour || *d > 180*24*time.Hour) {
+		errs = append(errs, errors.New("account_settings: peer_login_expiration must be between 1h and 180 days"))
+	}
+	if d := c.AccountSettings.PeerInactivityExpiration; d != nil && (*d < 10*time.Minute || *d > 180*24*time.Hour) {
+		errs = append(errs, errors.New("account_settings: peer_inactivity_expiration must be between 10m and 180 days"))
+	}
+	return errs
+}
+
+func (c CombinedConfig) validateNetworkRoutes() []error {
+	var errs []error
+	for idx, r := range c.NetworkRoutes {
+		name := r.NetworkID
+		if name == "" {
+			errs = append(errs, fmt.Errorf("network_routes[%d]: network_id is required", idx))
+			name = fmt.Sprintf("[%d]", idx)
+		}
+		if (r.Peer == "") == (len(r.PeerGroups) == 0) {
+			errs = append(errs, fmt.Errorf("network_routes: %s: exactly one of peer and peer_groups must be set", name))
+		}
+		if (r.Network == "") == (len(r.Domains) == 0) {
+			errs = append(errs, fmt.Errorf("network_routes: %s: exactly one of network and domains must be set", name))
+		} else if r.Network != "" {
+			if _, err := netip.ParsePrefix(r.Network); err != nil {
+				errs = append(errs, fmt.Errorf("network_routes: %s: invalid network: %w", name, err))
+			}
+		}
+		if r.Metric < 1 || r.Metric > 9999 {
+			errs = append(errs, fmt.Errorf("network_routes: %s: metric must be between 1 and 9999", name))
+		}
+		if len(r.Groups) == 0 {
+			errs = append(errs, fmt.Errorf("network_routes: %s: groups is required", name))
+		}
+	}
+	return errs
+}
+
+// validateExitNodes expects exit nodes to be expanded into network routes, so
+// fields shared with routes are validated by validateNetworkRoutes
+func (c CombinedConfig) validateExitNodes() []error {
+	var errs []error
+	names := make(map[string]bool)
+	for _, e := range c.ExitNodes {
+		if names[e.Name] {
+			errs = append(errs, fmt.Errorf("exit_nodes: duplicate exit node %s", e.Name))
+		}
+		names[e.Name] = true
+		if e.Priority < 0 || e.Priority > 9999 {
+			errs = append(errs, fmt.Errorf("exit_nodes: %s: priority must be 0 (default) or between 1 and 9999", e.Name))
+		}
+	}
+
+	// A group with multiple default routes of the same metric gets a random one
+	type defaultRoute struct {
+		group, network string
+		metric         int
+	}
+	seen := make(map[defaultRoute]string)
+	for _, r := range c.NetworkRoutes {
+		if !r.Enabled || (r.Network != DefaultRouteIPv4 && r.Network != DefaultRouteIPv6) {
+			continue
+		}
+		for _, g := range util.Unique(r.Groups) {
+			key := defaultRoute{group: g, network: r.Network, metric: r.Metric}
+			if other, ok := seen[key]; ok {
+				errs = append(errs, fmt.Errorf("exit_nodes: group %s gets conflicting default routes %s from %s and %s with the same priority %d", g, r.Network, other, r.NetworkID, r.Metric))
+				continue
+			}
+			seen[key] = r.NetworkID
+		}
+	}
+	return errs
+}
+
+func (c CombinedConfig) validateNetworks() []error {
+	var errs []error
+	refs := make(map[string]bool)
+	networks := make(map[string]bool)
+	for _, n := range c.Networks {
+		if n.Name == "" {
+			errs = append(errs, errors.New("networks: network with empty name"))
+			continue
+		}
+		if networks[n.Name] {
+			errs = append(errs, fmt.Errorf("networks: duplicate network %s", n.Name))
+		}
+		networks[n.Name] = true
+		for _, r := range n.Resources {
+			ref := ResourceRef(n.Name, r.Name)
+			if r.Name == "" {
+				errs = append(errs, fmt.Errorf("networks: %s: resource with empty name", n.Name))
+				continue
+			}
+			if refs[ref] {
+				errs = append(errs, fmt.Errorf("networks: %s: duplicate resource %s", n.Name, r.Name))
+			}
+			refs[ref] = true
+			if r.Address == "" {
+				errs = append(errs, fmt.Errorf("networks: %s: resource %s has no address", n.Name, r.Name))
+			}
+		}
+		for idx, r := range n.Routers {
+			if (r.Peer == "") == (len(r.PeerGroups) == 0) {
+				errs = append(errs, fmt.Errorf("networks: %s routers[%d]: exactly one of peer and peer_groups must be set", n.Name, idx))
+			}
+			if r.Metric < 1 || r.Metric > 9999 {
+				errs = append(errs, fmt.Errorf("networks: %s routers[%d]: metric must be between 1 and 9999", n.Name, idx))
+			}
+		}
+	}
+
+	for _, p := range c.Policies {
+		if p.DestinationResource == "" {
+			continue
+		}
+		if len(p.Destinations) > 0 {
+			errs = append(errs, fmt.Errorf("policies: %s: destinations and destination_resource are mutually exclusive", p.Name))
+		}
+		if !refs[p.DestinationResource] {
+			errs = append(errs, fmt.Errorf("policies: %s: destination_resource %s is not defined in networks", p.Name, p.DestinationResource))
+		}
+	}
+	return errs
+}
+
+func (c CombinedConfig) validatePostureChecks() []error {
+	var errs []error
+	names := make(map[string]bool)
+	validAction := func(action string) bool {
+		return action == PostureCheckAllow || action == PostureCheckDeny
+	}
+	for _, p := range c.PostureChecks {
+		if names[p.Name] {
+			errs = append(errs, fmt.Errorf("posture_checks: duplicate posture check %s", p.Name))
+		}
+		names[p.Name] = true
+
+		checks := p.Normalize().Checks
+		if checks.NBVersionCheck == nil && checks.OSVersionCheck == nil && checks.GeoLocationCheck == nil &&
+			checks.PeerNetworkRangeCheck == nil && checks.ProcessCheck == nil {
+			errs = append(errs, fmt.Errorf("posture_checks: %s: no checks defined", p.Name))
+		}
+		if checks.GeoLocationCheck != nil {
+			if len(checks.GeoLocationCheck.Locations) == 0 {
+				errs = append(errs, fmt.Errorf("posture_checks: %s: geo_location_check requires locations", p.Name))
+			}
+			for _, l := range checks.GeoLocationCheck.Locations {
+				if len(l.CountryCode) != 2 {
+					errs = append(errs, fmt.Errorf("posture_checks: %s: invalid country_code %q", p.Name, l.CountryCode))
+				}
+			}
+			if !validAction(checks.GeoLocationCheck.Action) {
+				errs = append(errs, fmt.Errorf("posture_checks: %s: geo_location_check action must be allow or deny, got %q", p.Name, checks.GeoLocationCheck.Action))
+			}
+		}
+		if checks.PeerNetworkRangeCheck != nil {
+			if len(checks.PeerNetworkRangeCheck.Ranges) == 0 {
+				errs = append(errs, fmt.Errorf("posture_checks: %s: peer_network_range_check requires ranges", p.Name))
+			}
+			for _, r := range checks.PeerNetworkRangeCheck.Ranges {
+				if _, err := netip.ParsePrefix(r); err != nil {
+					errs = append(errs, fmt.Errorf("posture_checks: %s: invalid range: %w", p.Name, err))
+				}
+			}
+			if !validAction(checks.PeerNetworkRangeCheck.Action) {
+				errs = append(errs, fmt.Errorf("posture_checks: %s: peer_network_range_check action must be allow or deny, got %q", p.Name, checks.PeerNetworkRangeCheck.Action))
+			}
+		}
+		if checks.ProcessCheck != nil {
+			if len(checks.ProcessCheck.Processes) == 0 {
+				errs = append(errs, fmt.Errorf("posture_checks: %s: process_check requires processes", p.Name))
+			}
+			for idx, proc := range checks.ProcessCheck.Processes {
+				if proc.LinuxPath == "" && proc.MacPath == "" && proc.WindowsPath == "" {
+					errs = append(errs, fmt.Errorf("posture_checks: %s: process_check processes[%d] has no path", p.Name, idx))
+				}
+			}
+		}
+	}
+	return errs
+}
+
+func (c CombinedConfig) validateDNS() []error {
+	var errs []error
+	if c.DNS != nil && c.DNS.DisableFor != nil && c.DNS.LegacyDisableFor != nil {
+		errs = append(errs, errors.New("dns: disable_for and disableFor are mutually exclusive"))
+	}
+
+	names := make(map[string]bool)
+	for _, ns := range c.Nameservers {
+		if ns.Name == "" {
+			errs = append(errs, errors.New("nameservers: nameserver group with empty name"))
+			continue
+		}
+		if names[ns.Name] {
+			errs = append(errs, fmt.Errorf("nameservers: duplicate nameserver group %s", ns.Name))
+		}
+		names[ns.Name] = true
+
+		if len(ns.Nameservers) < 1 || len(ns.Nameservers) > 3 {
+			errs = append(errs, fmt.Errorf("nameservers: %s: 1 to 3 nameservers are required, got %d", ns.Name, len(ns.Nameservers)))
+		}
+		for _, server := range ns.Nameservers {
+			if _, err := netip.ParseAddr(server.IP); err != nil {
+				errs = append(errs, fmt.Errorf("nameservers: %s: invalid ip: %w", ns.Name, err))
+			}
+			if server.NSType != "udp" {
+				errs = append(errs, fmt.Errorf("nameservers: %s: ns_type of %s must be udp", ns.Name, server.IP))
+			}
+			if server.Port < 1 || server.Port > 65535 {
+				errs = append(errs, fmt.Errorf("nameservers: %s: port of %s must be between 1 and 65535", ns.Name, server.IP))
+			}
+		}
+		if ns.Primary == (len(ns.Domains) > 0) {
+			errs = append(errs, fmt.Errorf("nameservers: %s: exactly one of primary and domains must be set", ns.Name))
+		}
+		if len(ns.Groups) == 0 {
+			errs = append(errs, fmt.Errorf("nameservers: %s: groups is required", ns.Name))
+		}
+	}
+	return errs
+}
+
+func (c CombinedConfig) validateDNSZones() []error {
+	var errs []error
+	domains := make(map[string]bool)
+	for _, z := range c.DNSZones {
+		domain := NormalizeDomain(z.Domain)
+		if domain == "" {
+			errs = append(errs, errors.New("dns_zones: zone with empty domain"))
+			continue
+		}
+		if domains[domain] {
+			errs = append(errs, fmt.Errorf("dns_zones: duplicate zone %s", z.Domain))
+		}
+		domains[domain] = true
+		if len(z.DistributionGroups) == 0 {
+			errs = append(errs, fmt.Errorf("dns_zones: %s: distribution_groups is required", z.Domain))
+		}
+
+		types := make(map[string][]string)
+		keys := make(map[string]bool)
+		for _, r := range z.Records {
+			name := NormalizeDomain(r.Name)
+			if name != domain && !strings.HasSuffix(name, "."+domain) {
+				errs = append(errs, fmt.Errorf("dns_zones: %s: record %s is outside of the zone", z.Domain, r.Name))
+			}
+			if keys[r.Key()] {
+				errs = append(errs, fmt.Errorf("dns_zones: %s: duplicate record %s", z.Domain, r.Key()))
+			}
+			keys[r.Key()] = true
+			types[name] = append(types[name], r.Type)
+			if r.TTL < 0 {
+				errs = append(errs, fmt.Errorf("dns_zones: %s: record %s has negative ttl", z.Domain, r.Name))
+			}
+
+			switch r.Type {
+			case DNSRecordA, DNSRecordAAAA:
+				addr, err := netip.ParseAddr(r.Content)
+				if err != nil || addr.Is4() != (r.Type == DNSRecordA) {
+					errs = append(errs, fmt.Errorf("dns_zones: %s: record %s has invalid %s content %q", z.Domain, r.Name, r.Type, r.Content))
+				}
+			case DNSRecordCNAME:
+				if _, err := netip.ParseAddr(r.Content); err == nil || r.Content == "" {
+					errs = append(errs, fmt.Errorf("dns_zones: %s: record %s CNAME content must be a domain name", z.Domain, r.Name))
+				}
+			default:
+				errs = append(errs, fmt.Errorf("dns_zones: %s: record %s has unsupported type %q", z.Domain, r.Name, r.Type))
+			}
+		}
+		for name, t := range types {
+			if slices.Contains(t, DNSRecordCNAME) && len(t) > 1 {
+				errs = append(errs, fmt.Errorf("dns_zones: %s: CNAME record %s cannot coexist with other records", z.Domain, name))
+			}
+		}
+	}
+	return errs
+}
+
+func (c CombinedConfig) validateTimeBound() (warnings []string, errs []error) {
+	for _, e := range c.TimeBoundEntries() {
+		if e.ValidFrom != nil && e.ValidUntil != nil && !e.ValidFrom.Before(*e.ValidUntil) {
+			errs = append(errs, fmt.Errorf("%s: valid_from must be before valid_until", e.Key))
+		}
+	}
+	for _, u := range c.Users {
+		for _, g := range u.TemporaryGroups {
+			if g.Group == "" {
+				errs = append(errs, fmt.Errorf("users: %s: temporary group with empty name", u.Email))
+			}
+		}
+	}
+	for _, p := range c.Peers {
+		for _, g := range p.TemporaryGroups {
+			if g.Group == "" {
+				errs = append(errs, fmt.Errorf("peers: %s: temporary group with empty name", p.ID))
+			}
+		}
+		// Groups of user peers follow their user unless set individually
+		if len(p.TemporaryGroups) > 0 && !c.Config.IndividualPeerGroups {
+			warnings = append(warnings, fmt.Sprintf("peers: %s: temporary_groups only apply to setup key peers unless config.individualPeerGroups is set", p.ID))
+		}
+	}
+	for _, p := range c.Policies {
+		if p.Schedule == nil {
+			continue
+		}
+		if err := p.Schedule.Validate(); err != nil {
+			errs = append(errs, fmt.Errorf("policies: %s: schedule: %w", p.Name, err))
+		}
+	}
+	if c.Config.ExpiryNotice < 0 {
+		errs = append(errs, errors.New("config.expiryNotice must not be negative"))
+	}
+	return warnings, errs
+}
@@ -0,0 +1,100 @@
+package data
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/mrsool/netbird-gitops/pkg/util"
+)
+
+// Validity time window an entry is active in, open ended if unset
+type Validity struct {
+	ValidFrom  *time.Time `yaml:"valid_from" json:"-"`
+	ValidUntil *time.Time `yaml:"valid_until" json:"-"`
+}
+
+// Active returns if now is within the validity window
+func (v Validity) Active(now time.Time) bool {
+	return (v.ValidFrom == nil || !now.Before(*v.ValidFrom)) &&
+		(v.ValidUntil == nil || now.Before(*v.ValidUntil))
+}
+
+// TemporaryGroup group membership valid for a limited time
+type TemporaryGroup struct {
+	Group    string `yaml:"group"`
+	Validity `yaml:",inline"`
+}
+
+// Kinds of time-bound entries
+const (
+	TimeBoundPolicy = "policy"
+	TimeBoundUser   = "user"
+	TimeBoundPeer   = "peer"
+)
+
+// TimeBound entry of the config with a validity window
+type TimeBound struct {
+	// Key identifies the entry across syncs
+	Key string
+	// Kind of entry, Name is the policy name, user email or peer ID and Group
+	// the temporary group of users and peers
+	Kind  string
+	Name  string
+	Group string
+	// Description human readable description for notifications
+	Description string
+	Validity
+	Schedule *Schedule
+}
+
+// ActiveAt returns if the entry is within its validity and schedule at now
+func (t TimeBound) ActiveAt(now time.Time) bool {
+	return t.Active(now) && (t.Schedule == nil || t.Schedule.Active(now))
+}
+
+// TimeBoundEntries returns policies and temporary group memberships with a
+// validity window or schedule
+func (c CombinedConfig) TimeBoundEntries() []TimeBound {
+	var ret []TimeBound
+	bounded := func(v Validity) bool { return v.ValidFrom != nil || v.ValidUntil != nil }
+	for _, p := range c.Policies {
+		if bounded(p.Validity) || p.Schedule != nil {
+			ret = append(ret, TimeBound{Key: "policy/" + p.Name, Kind: TimeBoundPolicy, Name: p.Name, Description: fmt.Sprintf("Policy %s", p.Name), Validity: p.Validity, Schedule: p.Schedule})
+		}
+	}
+	for _, u := range c.Users {
+		for _, g := range u.TemporaryGroups {
+			ret = append(ret, TimeBound{Key: "user/" + u.Email + "/" + g.Group, Kind: TimeBoundUser, Name: u.Email, Group: g.Group, Description: fmt.Sprintf("Membership of user %s in group %s", u.Email, g.Group), Validity: g.Validity})
+		}
+	}
+	for _, p := range c.Peers {
+		for _, g := range p.TemporaryGroups {
+			ret = append(ret, TimeBound{Key: "peer/" + p.ID + "/" + g.Group, Kind: TimeBoundPeer, Name: p.ID, Group: g.Group, Description: fmt.Sprintf("Membership of peer %s in group %s", p.ID, g.Group), Validity: g.Validity})
+		}
+	}
+	return ret
+}
+
+// ApplyValidity disables policies and adds temporary groups according to
+// their validity and schedule at now
+func (c *CombinedConfig) ApplyValidity(now time.Time) {
+	for idx, p := range c.Policies {
+		if !p.ActiveAt(now) {
+			c.Policies[idx].Enabled = false
+		}
+	}
+	for idx, u := range c.Users {
+		for _, g := range u.TemporaryGroups {
+			if g.Active(now) {
+				c.Users[idx].Groups = util.Unique(append(c.Users[idx].Groups, g.Group))
+			}
+		}
+	}
+	for idx, p := range c.Peers {
+		for _, g := range p.TemporaryGroups {
+			if g.Active(now) {
+				c.Peers[idx].GroupNames = util.Unique(append(c.Peers[idx].GroupNames, g.Group))
+			}
+		}
+	}
+}
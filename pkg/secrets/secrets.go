@@ -0,0 +1,160 @@
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"os"
+	"path"
+	"regexp"
+	"time"
+)
+
+// webhookTimeout bounds webhook requests so a hung webhook doesn't stall syncs
+const webhookTimeout = 30 * time.Second
+
+// Sink destination for generated secrets such as access tokens
+type Sink interface {
+	Write(ctx context.Context, name, value string) error
+}
+
+// Reverter is implemented by sinks which can undo a write, restoring the
+// previously stored value
+type Reverter interface {
+	WriteRevertible(ctx context.Context, name, value string) (revert func() error, err error)
+}
+
+var unsafeFileChars = regexp.MustCompile(`[^A-Za-z0-9._-]`)
+
+// FileSink writes each secret to its own file within Dir
+type FileSink struct {
+	Dir string
+}
+
+// NewFileSink returns a new FileSink writing to dir
+func NewFileSink(dir string) *FileSink {
+	return &FileSink{Dir: dir}
+}
+
+// Write writes secret value to a file named after the secret
+func (f FileSink) Write(ctx context.Context, name, value string) error {
+	_, err := f.WriteRevertible(ctx, name, value)
+	return err
+}
+
+// WriteRevertible writes secret value to a file named after the secret, revert
+// restores the previous file content or removes the file if there was none
+func (f FileSink) WriteRevertible(_ context.Context, name, value string) (func() error, error) {
+	err := os.MkdirAll(f.Dir, 0o700)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to create secrets directory: %w", err)
+	}
+
+	filePath := path.Join(f.Dir, unsafeFileChars.ReplaceAllString(name, "_"))
+	previous, err := os.ReadFile(filePath)
+	existed := err == nil
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("Failed to read secret %s: %w", name, err)
+	}
+
+	err = os.WriteFile(filePath, []byte(value), 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to write secret %s: %w", name, err)
+	}
+
+	return func() error {
+		if existed {
+			err = os.WriteFile(filePath, previous, 0o600)
+		} else {
+			err = os.Remove(filePath)
+		}
+		if err != nil {
+			return fmt.Errorf("Failed to revert secret %s: %w", name, err)
+		}
+		return nil
+	}, nil
+}
+
+// WebhookSink posts each secret as JSON {"name": ..., "value": ...} to URL
+type WebhookSink struct {
+	URL    string
+	client *http.Client
+}
+
+// NewWebhookSink returns a new WebhookSink posting to url
+func NewWebhookSink(url string) *WebhookSink {
+	return &WebhookSink{
+		URL:    url,
+		client: &http.Client{Timeout: webhookTimeout},
+	}
+}
+
+// Write posts secret to the webhook
+func (w WebhookSink) Write(ctx context.Context, name, value string) error {
+	body, err := json.Marshal(map[string]string{
+		"name":  name,
+		"value": value,
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", w.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Add("Content-Type", "application/json")
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("Failed to publish secret %s: %w", name, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode > 299 {
+		return fmt.Errorf("Failed to publish secret %s: status code %d", name, resp.StatusCode)
+	}
+	return nil
+}
+
+// MultiSink writes secrets to all sinks
+type MultiSink []Sink
+
+// Write writes secret to each sink, stopping at the first error. Sinks
+// implementing Reverter are written first and reverted if a later sink fails,
+// so they don't keep a secret that was never fully published
+func (m MultiSink) Write(ctx context.Context, name, value string) error {
+	var reverts []func() error
+	rollback := func(err error) error {
+		errs := []error{err}
+		for i := len(reverts) - 1; i >= 0; i-- {
+			errs = append(errs, reverts[i]())
+		}
+		return errors.Join(errs...)
+	}
+
+	for _, s := range m {
+		r, ok := s.(Reverter)
+		if !ok {
+			continue
+		}
+		revert, err := r.WriteRevertible(ctx, name, value)
+		if err != nil {
+			return rollback(err)
+		}
+		reverts = append(reverts, revert)
+	}
+	for _, s := range m {
+		if _, ok := s.(Reverter); ok {
+			continue
+		}
+		if err := s.Write(ctx, name, value); err != nil {
+			return rollback(err)
+		}
+	}
+	return nil
+}
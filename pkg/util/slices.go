@@ -56,3 +56,10 @@ func Select[A ~[]S, S interface{}](arr A, cmp func(S) bool) A {
 	}
 	return ret
 }
+
+// Unique returns sorted arr with duplicates removed
+func Unique[A ~[]S, S cmp.Ordered](arr A) A {
+	ret := slices.Clone(arr)
+	slices.Sort(ret)
+	return slices.Compact(ret)
+}
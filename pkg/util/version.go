@@ -0,0 +1,94 @@
+package util
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// CompareVersions compares dotted version strings numerically, returning -1, 0
+// or 1. A leading "v" and pre-release/build suffixes are ignored
+func CompareVersions(a, b string) int {
+	pa, pb := versionParts(a), versionParts(b)
+	for len(pa) < len(pb) {
+		pa = append(pa, 0)
+	}
+	for len(pb) < len(pa) {
+		pb = append(pb, 0)
+	}
+	for i := range pa {
+		if pa[i] < pb[i] {
+			return -1
+		}
+		if pa[i] > pb[i] {
+			return 1
+		}
+	}
+	return 0
+}
+
+func versionParts(v string) []int {
+	v = strings.TrimPrefix(strings.TrimSpace(v), "v")
+	if idx := strings.IndexAny(v, "-+ "); idx >= 0 {
+		v = v[:idx]
+	}
+	var ret []int
+	for _, s := range strings.Split(v, ".") {
+		end := 0
+		for end < len(s) && s[end] >= '0' && s[end] <= '9' {
+			end++
+		}
+		n, _ := strconv.Atoi(s[:end])
+		ret = append(ret, n)
+	}
+	return ret
+}
+
+// VersionSatisfies checks version against constraint, a version optionally
+// prefixed by one of the operators >=, <=, >, <, =, !=. Errors on unknown
+// operators and non-numeric constraint versions
+func VersionSatisfies(version, constraint string) (bool, error) {
+	constraint = strings.TrimSpace(constraint)
+	op := ""
+	for _, o := range []string{">=", "<=", "!=", ">", "<", "="} {
+		if strings.HasPrefix(constraint, o) {
+			op = o
+			break
+		}
+	}
+	target := strings.TrimSpace(strings.TrimPrefix(constraint, op))
+	if err := validVersion(target); err != nil {
+		return false, fmt.Errorf("invalid version constraint %q: %w", constraint, err)
+	}
+
+	cmp := CompareVersions(version, target)
+	switch op {
+	case ">=":
+		return cmp >= 0, nil
+	case "<=":
+		return cmp <= 0, nil
+	case "!=":
+		return cmp != 0, nil
+	case ">":
+		return cmp > 0, nil
+	case "<":
+		return cmp < 0, nil
+	default:
+		return cmp == 0, nil
+	}
+}
+
+// validVersion checks v is a dotted numeric version, optionally prefixed by
+// "v" and suffixed by a pre-release/build suffix
+func validVersion(v string) error {
+	v = strings.TrimPrefix(v, "v")
+	if idx := strings.IndexAny(v, "-+"); idx >= 0 {
+		v = v[:idx]
+	}
+	for _, s := range strings.Split(v, ".") {
+		if _, err := strconv.ParseUint(s, 10, 32); err != nil {
+			return fmt.Errorf("non-numeric version part %q", s)
+		}
+	}
+	return nil
+}
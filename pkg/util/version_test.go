@@ -0,0 +1,57 @@
+package util
+
+import "testing"
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"0.28.0", "0.28.0", 0},
+		{"14.3", "14.3.0", 0},
+		{"v0.28.1", "0.28.0", 1},
+		{"0.9.0", "0.28.0", -1},
+		{"0.28.0-dev", "0.28.0", 0},
+		{"1.0.0+build", "1.0.1", -1},
+	}
+	for _, tt := range tests {
+		if got := CompareVersions(tt.a, tt.b); got != tt.want {
+			t.Errorf("CompareVersions(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestVersionSatisfies(t *testing.T) {
+	tests := []struct {
+		version, constraint string
+		want                bool
+		wantErr             bool
+	}{
+		{"0.28.0", ">=0.28.0", true, false},
+		{"0.27.9", ">=0.28.0", false, false},
+		{"0.28.0", "<=0.28", true, false},
+		{"0.28.1", "<0.28.1", false, false},
+		{"0.29.0", "> 0.28.0", true, false},
+		{"0.28.0", "=0.28.0", true, false},
+		{"0.28.0", "!=0.28.0", false, false},
+		{"0.28.0", "0.28", true, false},
+		{"0.28.0", "v0.28.0", true, false},
+		{"0.28.0", ">=0.28.0-rc1", true, false},
+		{"0.28.0", "~0.28", false, true},
+		{"0.28.0", ">=abc", false, true},
+		{"0.28.0", "=>0.28", false, true},
+		{"0.28.0", ">=0..1", false, true},
+		{"0.28.0", ">=", false, true},
+		{"0.28.0", "", false, true},
+	}
+	for _, tt := range tests {
+		got, err := VersionSatisfies(tt.version, tt.constraint)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("VersionSatisfies(%q, %q) error = %v, wantErr %v", tt.version, tt.constraint, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("VersionSatisfies(%q, %q) = %v, want %v", tt.version, tt.constraint, got, tt.want)
+		}
+	}
+}